@@ -0,0 +1,7 @@
+package main
+
+import "go.ngs.io/internal/cli"
+
+func main() {
+	cli.Main()
+}
@@ -5,6 +5,9 @@ go 1.22
 require (
 	github.com/cli/go-gh/v2 v2.11.1
 	github.com/spf13/pflag v1.0.5
+	github.com/yuin/goldmark v1.7.8
+	golang.org/x/mod v0.20.0
+	golang.org/x/net v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
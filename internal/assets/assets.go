@@ -0,0 +1,195 @@
+// Package assets mirrors images referenced by package READMEs into the
+// site's static directory so pages do not hotlink third-party hosts.
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.ngs.io/internal/readme"
+)
+
+// DefaultMaxSize is the largest image mirrored by default.
+const DefaultMaxSize = 2 << 20
+
+// URLPrefix is the site path mirrored assets are served from.
+const URLPrefix = "/assets/"
+
+// allowedTypes maps the accepted image content types to file extensions.
+var allowedTypes = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/avif":    ".avif",
+	"image/svg+xml": ".svg",
+}
+
+// unsafeSVGPattern matches scripts and event handlers, which would run on
+// the site's origin when a mirrored SVG is opened directly.
+var unsafeSVGPattern = regexp.MustCompile(`(?i)<script|\son[a-z]+\s*=|javascript:`)
+
+// Mirror downloads images into <StaticDir>/assets/<package>/ using
+// content-hashed file names. With DryRun, images are downloaded and checked
+// to name them but nothing is written.
+type Mirror struct {
+	StaticDir string
+	MaxSize   int64
+	Client    *http.Client
+	DryRun    bool
+}
+
+func NewMirror(staticDir string) *Mirror {
+	return &Mirror{
+		StaticDir: staticDir,
+		MaxSize:   DefaultMaxSize,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Rewrite mirrors every absolute image URL of a README for the named
+// package and points the references at the local copies. Images that cannot
+// be mirrored keep their original URL and are reported as warnings.
+func (m *Mirror) Rewrite(name, markdown string) (string, []string) {
+	var warnings []string
+	mirrored := map[string]string{}
+
+	body := readme.MapImages(markdown, func(ref string) string {
+		if local, ok := mirrored[ref]; ok {
+			return local
+		}
+
+		u, err := url.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return ref
+		}
+
+		local, err := m.fetch(name, ref)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", ref, err))
+			return ref
+		}
+
+		mirrored[ref] = local
+		return local
+	})
+
+	return body, warnings
+}
+
+func (m *Mirror) fetch(name, ref string) (string, error) {
+	resp, err := m.Client.Get(ref)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	ext, ok := allowedTypes[mediaType]
+	if !ok {
+		return "", fmt.Errorf("content type %q is not allowed", mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, m.MaxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > m.MaxSize {
+		return "", fmt.Errorf("larger than %d bytes", m.MaxSize)
+	}
+	if ext == ".svg" && unsafeSVGPattern.Match(data) {
+		return "", fmt.Errorf("SVG contains scripts")
+	}
+
+	sum := sha256.Sum256(data)
+	filename := fmt.Sprintf("%x%s", sum[:8], ext)
+	if m.DryRun {
+		return path.Join(URLPrefix, name, filename), nil
+	}
+	dir := filepath.Join(m.StaticDir, "assets", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	filePath := filepath.Join(dir, filename)
+	if existing, err := os.ReadFile(filePath); err != nil || !bytes.Equal(existing, data) {
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+
+	return path.Join(URLPrefix, name, filename), nil
+}
+
+// Dir returns the directory of the mirrored assets of a package.
+func (m *Mirror) Dir(name string) string {
+	return filepath.Join(m.StaticDir, "assets", name)
+}
+
+// Prune removes mirrored assets no longer referenced by the given package
+// bodies, keyed by package name. With removeOrphans, asset directories of
+// packages missing from bodies are removed as well. It returns the removed
+// paths.
+func (m *Mirror) Prune(bodies map[string]string, removeOrphans bool) ([]string, error) {
+	root := filepath.Join(m.StaticDir, "assets")
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assets directory: %w", err)
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+
+		body, ok := bodies[entry.Name()]
+		if !ok {
+			if removeOrphans {
+				if err := os.RemoveAll(dir); err != nil {
+					return removed, fmt.Errorf("failed to remove %s: %w", dir, err)
+				}
+				removed = append(removed, dir)
+			}
+			continue
+		}
+
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return removed, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, file := range files {
+			if strings.Contains(body, path.Join(URLPrefix, entry.Name(), file.Name())) {
+				continue
+			}
+			filePath := filepath.Join(dir, file.Name())
+			if err := os.Remove(filePath); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", filePath, err)
+			}
+			removed = append(removed, filePath)
+		}
+	}
+
+	return removed, nil
+}
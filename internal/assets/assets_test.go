@@ -0,0 +1,233 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var (
+	pngData = []byte("\x89PNG\r\n\x1a\n fake image")
+	svgData = []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="1" height="1"/></svg>`)
+)
+
+// testServer serves images by path and counts the requests.
+type testServer struct {
+	*httptest.Server
+	requests map[string]int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	s := &testServer{requests: map[string]int{}}
+	files := map[string]struct {
+		contentType string
+		data        []byte
+	}{
+		"/logo.png":        {"image/png", pngData},
+		"/badge.svg":       {"image/svg+xml; charset=utf-8", svgData},
+		"/script.svg":      {"image/svg+xml", []byte(`<svg><script>alert(1)</script></svg>`)},
+		"/handler.svg":     {"image/svg+xml", []byte(`<svg><rect onload="alert(1)"/></svg>`)},
+		"/link.svg":        {"image/svg+xml", []byte(`<svg><a href="javascript:alert(1)"/></svg>`)},
+		"/page.html":       {"text/html", []byte("<html></html>")},
+		"/untyped":         {"", pngData},
+		"/large.png":       {"image/png", []byte(strings.Repeat("x", 101))},
+		"/limit.png":       {"image/png", []byte(strings.Repeat("y", 100))},
+		"/application.bin": {"application/octet-stream", pngData},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests[r.URL.Path]++
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		// An empty Content-Type header keeps net/http from sniffing one
+		w.Header()["Content-Type"] = []string{file.contentType}
+		w.Write(file.data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestMirror(t *testing.T, s *testServer) *Mirror {
+	m := NewMirror(t.TempDir())
+	m.Client = s.Client()
+	m.MaxSize = 100
+	return m
+}
+
+// hashedName returns the file name an image is mirrored as.
+func hashedName(data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%x%s", sum[:8], ext)
+}
+
+func TestRewrite(t *testing.T) {
+	s := newTestServer(t)
+	m := newTestMirror(t, s)
+
+	markdown := fmt.Sprintf("![Logo](%[1]s/logo.png)\n\n![Again](%[1]s/logo.png)\n\n<img src=\"%[1]s/badge.svg\">\n\n[Not an image](%[1]s/page.html)\n\n![Local](docs/local.png)\n", s.URL)
+	body, warnings := m.Rewrite("widget", markdown)
+	if len(warnings) > 0 {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	png := "/assets/widget/" + hashedName(pngData, ".png")
+	svg := "/assets/widget/" + hashedName(svgData, ".svg")
+	want := fmt.Sprintf("![Logo](%[2]s)\n\n![Again](%[2]s)\n\n<img src=\"%[3]s\">\n\n[Not an image](%[1]s/page.html)\n\n![Local](docs/local.png)\n", s.URL, png, svg)
+	if body != want {
+		t.Errorf("Rewrite =\n%s\nwant\n%s", body, want)
+	}
+
+	if s.requests["/logo.png"] != 1 {
+		t.Errorf("logo.png was requested %d times, want once", s.requests["/logo.png"])
+	}
+	if s.requests["/page.html"] != 0 {
+		t.Errorf("the link to page.html was fetched")
+	}
+
+	for file, data := range map[string][]byte{png: pngData, svg: svgData} {
+		got, err := os.ReadFile(filepath.Join(m.StaticDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(data) {
+			t.Errorf("%s = %q, want %q", file, got, data)
+		}
+	}
+}
+
+func TestRewriteDryRun(t *testing.T) {
+	s := newTestServer(t)
+	m := newTestMirror(t, s)
+	m.DryRun = true
+
+	body, warnings := m.Rewrite("widget", fmt.Sprintf("![Logo](%s/logo.png)\n", s.URL))
+	if len(warnings) > 0 {
+		t.Errorf("warnings = %v", warnings)
+	}
+	if want := "![Logo](/assets/widget/" + hashedName(pngData, ".png") + ")\n"; body != want {
+		t.Errorf("Rewrite = %q, want %q", body, want)
+	}
+	if _, err := os.Stat(m.Dir("widget")); err == nil {
+		t.Errorf("dry run wrote to %s", m.Dir("widget"))
+	}
+}
+
+func TestRewriteRejects(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		path    string
+		warning string
+	}{
+		{"/page.html", `content type "text/html" is not allowed`},
+		{"/application.bin", `content type "application/octet-stream" is not allowed`},
+		{"/untyped", `content type "" is not allowed`},
+		{"/large.png", "larger than 100 bytes"},
+		{"/script.svg", "SVG contains scripts"},
+		{"/handler.svg", "SVG contains scripts"},
+		{"/link.svg", "SVG contains scripts"},
+		{"/missing.png", "unexpected status 404 Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.path, "/"), func(t *testing.T) {
+			m := newTestMirror(t, s)
+			markdown := fmt.Sprintf("![Image](%s%s)\n", s.URL, tt.path)
+
+			body, warnings := m.Rewrite("widget", markdown)
+			if body != markdown {
+				t.Errorf("Rewrite changed the reference to %q", body)
+			}
+			want := []string{s.URL + tt.path + ": " + tt.warning}
+			if !reflect.DeepEqual(warnings, want) {
+				t.Errorf("warnings = %q, want %q", warnings, want)
+			}
+			if _, err := os.Stat(m.Dir("widget")); err == nil {
+				t.Errorf("rejected image was written to %s", m.Dir("widget"))
+			}
+		})
+	}
+}
+
+func TestRewriteMaxSize(t *testing.T) {
+	s := newTestServer(t)
+	m := newTestMirror(t, s)
+
+	body, warnings := m.Rewrite("widget", fmt.Sprintf("![Image](%s/limit.png)\n", s.URL))
+	if len(warnings) > 0 {
+		t.Fatalf("image of exactly MaxSize bytes was rejected: %v", warnings)
+	}
+	if want := fmt.Sprintf("![Image](/assets/widget/%s)\n", hashedName([]byte(strings.Repeat("y", 100)), ".png")); body != want {
+		t.Errorf("Rewrite = %q, want %q", body, want)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m := NewMirror(t.TempDir())
+	files := []string{
+		"widget/0000000000000001.png",
+		"widget/0000000000000002.png",
+		"gadget/0000000000000003.svg",
+		"retired/0000000000000004.png",
+	}
+	for _, file := range files {
+		path := filepath.Join(m.StaticDir, "assets", filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bodies := map[string]string{
+		"widget": "![Logo](/assets/widget/0000000000000001.png)",
+		"gadget": "<img src=\"/assets/gadget/0000000000000003.svg\">",
+	}
+
+	removed, err := m.Prune(bodies, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(m.StaticDir, "assets", "widget", "0000000000000002.png")}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("Prune = %v, want %v", removed, want)
+	}
+
+	removed, err = m.Prune(bodies, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{m.Dir("retired")}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("Prune with removeOrphans = %v, want %v", removed, want)
+	}
+
+	var remaining []string
+	filepath.WalkDir(filepath.Join(m.StaticDir, "assets"), func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			rel, _ := filepath.Rel(filepath.Join(m.StaticDir, "assets"), path)
+			remaining = append(remaining, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	sort.Strings(remaining)
+	if want := []string{"gadget/0000000000000003.svg", "widget/0000000000000001.png"}; !reflect.DeepEqual(remaining, want) {
+		t.Errorf("remaining assets = %v, want %v", remaining, want)
+	}
+}
+
+func TestPruneWithoutAssets(t *testing.T) {
+	removed, err := NewMirror(t.TempDir()).Prune(nil, true)
+	if err != nil || len(removed) > 0 {
+		t.Errorf("Prune = %v, %v; want nothing", removed, err)
+	}
+}
@@ -0,0 +1,465 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/assets"
+	"go.ngs.io/internal/github"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/modpath"
+	"go.ngs.io/internal/refresh"
+	"go.ngs.io/internal/source"
+)
+
+type addOptions struct {
+	importPath   string
+	repoURL      string
+	author       string
+	sourceName   string
+	cacheDir     string
+	usageFile    string
+	mirrorAssets bool
+	domain       string
+	fromDir      string
+
+	allowBuildErrors bool
+}
+
+// addResult is the JSON result of add.
+type addResult struct {
+	Name       string   `json:"name"`
+	Domain     string   `json:"domain"`
+	File       string   `json:"file"`
+	Files      []string `json:"files"` // The page and its localized pages
+	ImportPath string   `json:"import_path"`
+	RepoURL    string   `json:"repo_url"`
+	Version    string   `json:"version,omitempty"`
+}
+
+func addCommand() *command {
+	return &command{
+		name:    "add",
+		summary: "Add a new Go package to a vanity domain",
+		usage:   "<package-name> [options] | --from-dir <dir> [<package-name>] | --manifest <file> | --discover <owner>",
+		description: "With --manifest, the packages listed in a YAML or CSV file are added; with --discover, the repositories of a GitHub owner\n" +
+			"whose go.mod declares a module under a configured domain. Packages already in the registry are skipped.\n" +
+			"With --from-dir, metadata is read from a local git checkout without network access: the import path from go.mod,\n" +
+			"the repository from the origin remote and the package name from the import path unless given.",
+		examples: []string{
+			"mypackage --repo https://github.com/username/mypackage",
+			"tools --import-path go.ngs.io/tools --repo https://github.com/ngs/tools",
+			"mypackage --source git --repo file:///path/to/mypackage.git",
+			"mytool --usage-file cmd/mytool/main.go",
+			"mypackage --domain go.example.com",
+			"--from-dir ../mypackage",
+			"--manifest packages.yaml",
+			"--discover ngs --dry-run",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var (
+				opts     addOptions
+				manifest string
+				owner    string
+				dryRun   bool
+			)
+			fs.StringVar(&opts.importPath, "import-path", "", "Custom import path (default: <domain>/<package-name>)")
+			fs.StringVar(&opts.repoURL, "repo", "", "Repository URL (default: <forge>/<owner>/<package-name>)")
+			fs.StringVar(&opts.author, "author", "", "Package author name")
+			fs.StringVar(&opts.sourceName, "source", source.NameGitHub, "Metadata source: github or git (local bare mirror)")
+			fs.StringVar(&opts.cacheDir, "cache-dir", "", "Directory for git mirrors (default: user cache directory)")
+			fs.StringVar(&opts.usageFile, "usage-file", "", "Repository file to read --help style usage text from (e.g., cmd/tool/main.go)")
+			fs.BoolVar(&opts.mirrorAssets, "mirror-assets", false, "Download README images into static/ instead of hotlinking them, also on later updates")
+			fs.StringVar(&opts.domain, "domain", "", "Domain to add the package to (default: primary domain)")
+			fs.StringVar(&manifest, "manifest", "", "Add the packages listed in a YAML or CSV file")
+			fs.StringVar(&owner, "discover", "", "Add the modules under the configured domains found in the repositories of a GitHub owner")
+			fs.BoolVar(&dryRun, "dry-run", false, "With --manifest or --discover, only show the packages that would be added")
+			fs.StringVar(&opts.fromDir, "from-dir", "", "Read metadata from a local git checkout instead of --source")
+			fs.BoolVar(&opts.allowBuildErrors, "allow-build-errors", false, "Only warn when the site fails to build after the change")
+
+			return func(a *app, args []string) error {
+				bulk := manifest != "" || owner != ""
+				if manifest != "" && owner != "" {
+					return fmt.Errorf("--manifest and --discover cannot be used together")
+				}
+				if !dryRun {
+					release, err := a.lockRepository()
+					if err != nil {
+						return err
+					}
+					defer release()
+				}
+				if opts.fromDir != "" {
+					if bulk || len(args) > 1 {
+						return errUsage
+					}
+					checkout, err := source.NewCheckout(opts.fromDir)
+					if err != nil {
+						return err
+					}
+					name := ""
+					if len(args) == 1 {
+						name = args[0]
+					}
+					return a.addFromDir(opts, checkout, name)
+				}
+				if bulk && len(args) > 0 || !bulk && len(args) != 1 {
+					return errUsage
+				}
+				src, err := source.New(opts.sourceName, opts.cacheDir)
+				if err != nil {
+					return err
+				}
+				if !bulk {
+					return a.addPackage(opts, src, args[0])
+				}
+
+				var entries []manifestEntry
+				if manifest != "" {
+					entries, err = readManifest(manifest)
+				} else {
+					entries, err = a.discover(owner)
+				}
+				if err != nil {
+					return err
+				}
+				return a.addPackages(opts, src, entries, dryRun)
+			}
+		},
+	}
+}
+
+// addFromDir adds the package of a local checkout. The import path defaults
+// to the module path of its go.mod, the repository to its origin remote and
+// the name to the import path within its domain.
+func (a *app) addFromDir(opts addOptions, checkout *source.Checkout, name string) error {
+	if opts.importPath == "" {
+		modulePath, err := checkout.Module()
+		if err != nil {
+			return err
+		}
+		opts.importPath = modulePath
+	}
+	if opts.repoURL == "" {
+		repoURL, err := checkout.RemoteURL()
+		if err != nil {
+			return fmt.Errorf("%w; set the repository with --repo", err)
+		}
+		opts.repoURL = repoURL
+	}
+
+	if opts.domain == "" {
+		opts.domain = a.cfg.DomainOf(opts.importPath).Domain
+	}
+	if name == "" {
+		d, err := a.cfg.Lookup(opts.domain)
+		if err != nil {
+			return err
+		}
+		if name = nameInDomain(d, opts.importPath); name == "" {
+			return fmt.Errorf("import path %s is not under %s; give the package name", opts.importPath, d.Domain)
+		}
+	}
+
+	return a.addPackage(opts, checkout, name)
+}
+
+// createPackage writes the page of a new package and records it for the
+// release feeds.
+func (a *app) createPackage(opts addOptions, src source.Source, packageName string) (addResult, *hugo.Package, error) {
+	importPath, repoURL, author, usageFile := opts.importPath, opts.repoURL, opts.author, opts.usageFile
+
+	// Validate package name; it names the content file
+	if err := modpath.CheckName(packageName); err != nil {
+		return addResult{}, nil, err
+	}
+
+	d, err := a.cfg.Lookup(opts.domain)
+	if err != nil {
+		return addResult{}, nil, err
+	}
+
+	// Set default import path if not provided
+	if importPath == "" {
+		importPath = d.ImportPath(packageName)
+	}
+	if err := modpath.CheckImportPath(importPath, d.Domain); err != nil {
+		return addResult{}, nil, err
+	}
+
+	// Import paths of all domains; an import path is served by one domain only
+	registry, err := hugo.ImportPaths(a.cfg.ContentDomains())
+	if err != nil {
+		return addResult{}, nil, fmt.Errorf("failed to read registry import paths: %w", err)
+	}
+	if conflict := modpath.Conflict(importPath, registry); conflict == importPath {
+		return addResult{}, nil, fmt.Errorf("import path %s is already registered", importPath)
+	} else if conflict != "" {
+		return addResult{}, nil, fmt.Errorf("import path %s collides with registered %s", importPath, conflict)
+	}
+
+	// Validate repository URL if provided
+	if repoURL != "" {
+		if src.Name() == source.NameGitHub {
+			if _, _, err := github.ParseRepoURL(repoURL); err != nil {
+				return addResult{}, nil, fmt.Errorf("invalid repository URL: %w", err)
+			}
+		}
+	} else {
+		// Try to guess from package name
+		repoURL = d.RepoURL(packageName)
+	}
+
+	a.printf("Adding package '%s' from %s...\n", packageName, repoURL)
+
+	// Create package struct
+	pkg := &hugo.Package{
+		Title:            packageName,
+		ImportPath:       importPath,
+		RepoURL:          repoURL,
+		DocumentationURL: fmt.Sprintf("https://pkg.go.dev/%s", importPath),
+		Author:           author,
+		UsageFile:        usageFile,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+	}
+
+	// Fetch repository metadata
+	a.printf("Fetching repository metadata from %s...\n", src.Name())
+	repoInfo, err := src.Repository(repoURL)
+	if err != nil {
+		// Exit with error if repository doesn't exist
+		return addResult{}, nil, fmt.Errorf("failed to fetch repository metadata from %s: %w", repoURL, err)
+	}
+
+	// Update package with repository data
+	pkg.Description = repoInfo.Description
+	pkg.CreatedAt = repoInfo.CreatedAt
+	pkg.UpdatedAt = repoInfo.UpdatedAt
+	pkg.License = repoInfo.License
+	pkg.DefaultBranch = repoInfo.DefaultBranch
+
+	// Get author from repository owner if not provided
+	if author == "" && repoInfo.OwnerName != "" {
+		pkg.Author = repoInfo.OwnerName
+	} else if author == "" {
+		pkg.Author = repoInfo.OwnerLogin
+	}
+
+	// Fetch version history
+	versions, err := refresh.Versions(src, repoURL)
+	if err != nil {
+		a.printf("Warning: Could not fetch version history: %v\n", err)
+	} else if len(versions) > 0 {
+		pkg.Versions = versions
+		a.printf("Found %d versions\n", len(versions))
+	}
+
+	// Read retractions and deprecation from go.mod
+	modFile, err := refresh.ModFile(src, repoURL, importPath, pkg.Versions)
+	if err != nil {
+		a.printf("Warning: Could not read go.mod: %v\n", err)
+	} else {
+		refresh.MarkRetracted(pkg.Versions, modFile)
+		pkg.Deprecated = modFile.Deprecated
+		pkg.GoVersion = modFile.Go
+		pkg.Toolchain = modFile.Toolchain
+		pkg.Dependencies = modFile.DirectDependencies()
+		pkg.Requires = refresh.Requirements(modFile, importPath, registry)
+		if pkg.GoVersion != "" {
+			a.printf("Requires Go %s\n", pkg.GoVersion)
+		}
+		if pkg.Deprecated != "" {
+			a.printf("Warning: Module is deprecated: %s\n", pkg.Deprecated)
+		}
+	}
+
+	// Select latest version, skipping retracted ones and other major versions
+	version := refresh.LatestVersion(pkg.Versions, importPath)
+	if version == "" && len(pkg.Versions) == 0 {
+		version, err = src.LatestVersion(repoURL)
+		if err != nil {
+			a.printf("Warning: Could not fetch version information: %v\n", err)
+		}
+	} else if len(refresh.ModuleVersions(pkg.Versions, importPath)) == 0 {
+		a.printf("Warning: No version matches import path %s; tags such as %s belong to another major version\n",
+			importPath, pkg.Versions[0].Version)
+	}
+	if err := modpath.CheckVersion(importPath, version); err != nil {
+		return addResult{}, nil, err
+	}
+	if version != "" {
+		pkg.Version = version
+		a.printf("Found version: %s\n", version)
+	}
+
+	// Detect installable commands
+	commands, err := refresh.Commands(src, repoURL, pkg.Version, importPath)
+	if err != nil {
+		a.printf("Warning: Could not detect commands: %v\n", err)
+	} else {
+		pkg.Commands = commands
+		if len(commands) > 0 {
+			a.printf("Found commands: %s\n", strings.Join(commands, ", "))
+		}
+	}
+
+	// Collect Example functions
+	examples, err := refresh.Examples(src, repoURL, pkg.Version, importPath)
+	if err != nil {
+		a.printf("Warning: Could not collect examples: %v\n", err)
+	} else if len(examples) > 0 {
+		pkg.Examples = examples
+		a.printf("Found %d examples\n", len(examples))
+	}
+
+	// Read usage text
+	if usageFile != "" {
+		usage, err := refresh.Usage(src, repoURL, pkg.Version, usageFile)
+		if err != nil {
+			a.printf("Warning: Could not read usage text: %v\n", err)
+		} else {
+			pkg.Usage = usage
+			a.printf("Found usage text in %s\n", usageFile)
+		}
+	}
+
+	// Detect license from LICENSE files when the source has none
+	if pkg.License == "" {
+		license, note, err := refresh.License(src, repoURL, "")
+		if err != nil {
+			a.printf("Warning: Could not detect license: %v\n", err)
+		} else if license != "" {
+			pkg.License = license
+			pkg.LicenseNote = note
+			a.printf("Detected license: %s\n", license)
+		}
+	}
+
+	// Fetch README with links resolved against the repository
+	readme, sanitized, err := refresh.Readme(src, repoURL, pkg.DefaultBranch, pkg.Title, pkg.ImportPath)
+	if err != nil {
+		a.printf("Warning: Could not fetch README: %v\n", err)
+	} else if readme != "" {
+		pkg.Body = readme
+		a.println("Found README")
+		for _, change := range sanitized {
+			a.printf("Warning: Sanitized README: %s\n", change)
+		}
+	}
+
+	// Fetch localized READMEs such as README.ja.md
+	localized, sanitized, err := refresh.LocalizedReadmes(src, repoURL, pkg.DefaultBranch, hugo.Languages, pkg.Title, pkg.ImportPath)
+	if err != nil {
+		a.printf("Warning: Could not fetch localized READMEs: %v\n", err)
+	} else if languages := refresh.Localize(pkg, localized); len(languages) > 0 {
+		a.printf("Found localized READMEs: %s\n", strings.Join(languages, ", "))
+		for _, change := range sanitized {
+			a.printf("Warning: Sanitized README: %s\n", change)
+		}
+	}
+
+	// Create file path
+	filePath := d.PackagePath(packageName)
+
+	// Check if file already exists
+	if _, err := os.Stat(filePath); err == nil {
+		return addResult{}, nil, fmt.Errorf("package file already exists: %s", filePath)
+	}
+
+	// Mirror README images into the site
+	if opts.mirrorAssets {
+		pkg.MirrorAssets = true
+		mirror := assets.NewMirror(d.StaticDir)
+		var warnings []string
+		if pkg.Body != "" {
+			pkg.Body, warnings = mirror.Rewrite(packageName, pkg.Body)
+		}
+		for _, localization := range pkg.Localizations {
+			var localizedWarnings []string
+			localization.Body, localizedWarnings = mirror.Rewrite(packageName, localization.Body)
+			warnings = append(warnings, localizedWarnings...)
+		}
+		for _, warning := range warnings {
+			a.printf("Warning: Could not mirror image %s\n", warning)
+		}
+	}
+
+	// Write package file
+	if err := hugo.WritePackage(filePath, pkg); err != nil {
+		return addResult{}, nil, fmt.Errorf("failed to write package file: %w", err)
+	}
+
+	a.printf("✓ Created %s\n", filePath)
+
+	// Record the new package for the release feeds
+	release := hugo.Release{
+		Event:      hugo.EventAdded,
+		Name:       packageName,
+		ImportPath: importPath,
+		Version:    pkg.Version,
+		URL:        repoURL,
+		Date:       time.Now().UTC().Truncate(time.Second),
+	}
+	if releaseURL := src.ReleaseURL(repoURL, pkg.Version); pkg.Version != "" && releaseURL != "" {
+		release.URL = releaseURL
+	}
+	if err := hugo.AppendRelease(d.ReleasesPath(), release); err != nil {
+		return addResult{}, nil, fmt.Errorf("failed to record release: %w", err)
+	}
+
+	files := []string{filePath}
+	for _, lang := range hugo.Languages {
+		if _, ok := pkg.Localizations[lang]; ok {
+			files = append(files, hugo.LocalizedPath(filePath, lang))
+		}
+	}
+
+	return addResult{
+		Name:       packageName,
+		Domain:     d.Domain,
+		File:       filePath,
+		Files:      files,
+		ImportPath: pkg.ImportPath,
+		RepoURL:    pkg.RepoURL,
+		Version:    pkg.Version,
+	}, pkg, nil
+}
+
+func (a *app) addPackage(opts addOptions, src source.Source, packageName string) error {
+	result, pkg, err := a.createPackage(opts, src, packageName)
+	if err != nil {
+		return err
+	}
+
+	// Build site to validate
+	if err := a.checkSite(result.Domain, opts.allowBuildErrors); err != nil {
+		return err
+	}
+
+	// Print summary
+	a.println("\n=== Package Added Successfully ===")
+	a.printf("Name: %s\n", pkg.Title)
+	a.printf("Import Path: %s\n", pkg.ImportPath)
+	a.printf("Repository: %s\n", pkg.RepoURL)
+	if pkg.Version != "" {
+		a.printf("Version: %s\n", pkg.Version)
+	}
+	if pkg.Description != "" {
+		a.printf("Description: %s\n", pkg.Description)
+	}
+
+	files := strings.Join(result.Files, " ")
+	a.println("\nNext steps:")
+	a.println("1. Review the generated file:", files)
+	a.println("2. Commit the changes: git add", files, "&& git commit -m \"Add", packageName, "package\"")
+	a.println("3. Push to deploy: git push")
+
+	if a.json() {
+		return a.writeJSON(result)
+	}
+	return nil
+}
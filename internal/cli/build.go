@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/site"
+)
+
+// buildResult is a domain in the JSON result of build.
+type buildResult struct {
+	Domain    string            `json:"domain"`
+	OutputDir string            `json:"output_dir"`
+	OK        bool              `json:"ok"`
+	Errors    []site.BuildError `json:"errors,omitempty"` // Build errors by file and line
+}
+
+func buildCommand() *command {
+	return &command{
+		name:    "build",
+		summary: "Build the site of each domain into its output directory",
+		usage:   "[options] [-- hugo-flags...]",
+		description: "Each domain is built with Hugo with its own base URL, content and static directories.\n" +
+			"Arguments after -- are passed to hugo. Use serve to preview a domain locally.",
+		examples: []string{
+			"                           # Build all domains",
+			"--domain go.example.com    # Build one domain",
+			"-- --gc --minify           # Pass flags to hugo",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var domain string
+			fs.StringVar(&domain, "domain", "", "Build only this domain (default: all domains)")
+
+			return func(a *app, args []string) error {
+				return a.buildSites(domain, args)
+			}
+		},
+	}
+}
+
+func (a *app) buildSites(domain string, hugoArgs []string) error {
+	domains, err := a.cfg.Select(domain)
+	if err != nil {
+		return err
+	}
+
+	results := []buildResult{}
+	failed := 0
+	for _, d := range domains {
+		result := buildResult{Domain: d.Domain, OutputDir: d.OutputDir, OK: true}
+		err := site.Hugo{}.Build(a.cfg, d.Domain, site.BuildOptions{Args: hugoArgs, Log: a.buildLog()})
+		if err != nil {
+			result.OK = false
+			result.Errors = buildErrors(err)
+			a.printf("✗ %s - build failed\n", d.Domain)
+			for _, buildErr := range result.Errors {
+				a.printf("  %v\n", buildErr)
+			}
+			failed++
+		} else {
+			a.printf("✓ %s - built into %s\n", d.Domain, d.OutputDir)
+		}
+		results = append(results, result)
+	}
+
+	if a.json() {
+		if err := a.writeJSON(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d domains failed to build", failed)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/github"
+	"go.ngs.io/internal/gomod"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/modpath"
+	"go.ngs.io/internal/source"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestEntry is a package to add, as listed in a manifest. Empty fields
+// take the defaults of add.
+type manifestEntry struct {
+	Name       string `yaml:"name"`
+	ImportPath string `yaml:"import_path,omitempty"`
+	RepoURL    string `yaml:"repo,omitempty"`
+	Author     string `yaml:"author,omitempty"`
+	UsageFile  string `yaml:"usage_file,omitempty"`
+	Domain     string `yaml:"domain,omitempty"`
+}
+
+// manifestColumns are the CSV columns of a manifest, matching the YAML keys.
+var manifestColumns = []string{"name", "import_path", "repo", "author", "usage_file", "domain"}
+
+// bulkResult is a package in the JSON result of add --manifest and
+// add --discover.
+type bulkResult struct {
+	Name       string   `json:"name"`
+	Domain     string   `json:"domain"`
+	ImportPath string   `json:"import_path"`
+	RepoURL    string   `json:"repo_url"`
+	Status     string   `json:"status"` // new, exists, invalid, added or error
+	Message    string   `json:"message,omitempty"`
+	Files      []string `json:"files,omitempty"`
+}
+
+// readManifest reads the packages of a YAML list or a CSV file with a
+// header row; the format follows the file extension.
+func readManifest(path string) ([]manifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var entries []manifestEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+		}
+	case ".csv":
+		entries, err = parseManifestCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format: %s (use .yaml, .yml or .csv)", path)
+	}
+
+	for i, e := range entries {
+		if e.Name == "" {
+			return nil, fmt.Errorf("manifest %s: entry %d has no name", path, i+1)
+		}
+	}
+	return entries, nil
+}
+
+func parseManifestCSV(r io.Reader) ([]manifestEntry, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(manifestColumns, name) {
+			return nil, fmt.Errorf("unknown column %q (columns: %s)", name, strings.Join(manifestColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("missing name column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var entries []manifestEntry
+	for _, record := range records[1:] {
+		entries = append(entries, manifestEntry{
+			Name:       field(record, "name"),
+			ImportPath: field(record, "import_path"),
+			RepoURL:    field(record, "repo"),
+			Author:     field(record, "author"),
+			UsageFile:  field(record, "usage_file"),
+			Domain:     field(record, "domain"),
+		})
+	}
+	return entries, nil
+}
+
+// discover lists the repositories of a GitHub owner whose go.mod declares a
+// module under one of the configured domains.
+func (a *app) discover(owner string) ([]manifestEntry, error) {
+	a.printf("Listing repositories of %s...\n", owner)
+	repos, err := github.ListRepositories(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []manifestEntry
+	for _, repo := range repos {
+		if repo.Fork || repo.Archived {
+			a.debugf("Skipping %s: fork or archived\n", repo.Name)
+			continue
+		}
+		data, err := github.GetFile(owner, repo.Name, "go.mod", "")
+		if err != nil {
+			a.debugf("Skipping %s: %v\n", repo.Name, err)
+			continue
+		}
+		modFile, err := gomod.Parse(data)
+		if err != nil {
+			a.printf("Warning: %s: %v\n", repo.Name, err)
+			continue
+		}
+
+		d := a.cfg.DomainOf(modFile.Path)
+		name := nameInDomain(d, modFile.Path)
+		if name == "" {
+			a.debugf("Skipping %s: module %s is outside the configured domains\n", repo.Name, modFile.Path)
+			continue
+		}
+		entries = append(entries, manifestEntry{
+			Name:       name,
+			ImportPath: modFile.Path,
+			RepoURL:    repo.HTMLURL,
+			Domain:     d.Domain,
+		})
+	}
+	return entries, nil
+}
+
+// nameInDomain returns the package name of a module under domain d, e.g.
+// "tool" for go.ngs.io/tool/v2, or "" if the module is not under d.
+func nameInDomain(d *config.Config, modulePath string) string {
+	rest, ok := strings.CutPrefix(modulePath, d.Domain+"/")
+	if !ok || rest == "" {
+		return ""
+	}
+	if prefix, _, ok := module.SplitPathVersion(rest); ok && prefix != "" {
+		rest = prefix
+	}
+	return strings.ReplaceAll(rest, "/", "-")
+}
+
+// addPackages adds the packages of entries missing from the registry,
+// continuing past failures. The site of each domain receiving packages is
+// validated once at the end.
+func (a *app) addPackages(opts addOptions, src source.Source, entries []manifestEntry, dryRun bool) error {
+	registry, err := hugo.ImportPaths(a.cfg.ContentDomains())
+	if err != nil {
+		return fmt.Errorf("failed to read registry import paths: %w", err)
+	}
+
+	results := make([]bulkResult, 0, len(entries))
+	for _, e := range entries {
+		d, err := a.cfg.Lookup(e.Domain)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		result := bulkResult{Name: e.Name, Domain: d.Domain, ImportPath: e.ImportPath, RepoURL: e.RepoURL, Status: "new"}
+		if result.ImportPath == "" {
+			result.ImportPath = d.ImportPath(e.Name)
+		}
+		if result.RepoURL == "" {
+			result.RepoURL = d.RepoURL(e.Name)
+		}
+		if err := modpath.CheckName(e.Name); err != nil {
+			result.Status, result.Message = "invalid", err.Error()
+		} else if err := modpath.CheckImportPath(result.ImportPath, d.Domain); err != nil {
+			result.Status, result.Message = "invalid", err.Error()
+		} else if slices.Contains(registry, result.ImportPath) || len(existing(d.PackagePath(e.Name))) > 0 {
+			result.Status = "exists"
+		}
+		registry = append(registry, result.ImportPath) // Repeated entries count as existing
+		results = append(results, result)
+	}
+
+	w := tabwriter.NewWriter(a.log, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tIMPORT PATH\tREPOSITORY\tSTATUS")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.ImportPath, r.RepoURL, r.Status)
+	}
+	w.Flush()
+	for _, r := range results {
+		if r.Status == "invalid" {
+			a.printf("✗ %s - %s\n", r.Name, r.Message)
+		}
+	}
+
+	if dryRun {
+		if a.json() {
+			return a.writeJSON(results)
+		}
+		return nil
+	}
+
+	added, failed, existed := 0, 0, 0
+	domains := map[string]bool{}
+	for i, e := range entries {
+		switch results[i].Status {
+		case "exists":
+			existed++
+			continue
+		case "invalid":
+			failed++
+			continue
+		}
+		a.printf("\n=== %s ===\n", e.Name)
+		entryOpts := opts
+		entryOpts.importPath = results[i].ImportPath
+		entryOpts.repoURL = results[i].RepoURL
+		entryOpts.domain = results[i].Domain
+		if e.Author != "" {
+			entryOpts.author = e.Author
+		}
+		if e.UsageFile != "" {
+			entryOpts.usageFile = e.UsageFile
+		}
+
+		result, _, err := a.createPackage(entryOpts, src, e.Name)
+		if err != nil {
+			a.printf("✗ %s - %v\n", e.Name, err)
+			results[i].Status = "error"
+			results[i].Message = err.Error()
+			failed++
+			continue
+		}
+		results[i].Status = "added"
+		results[i].Files = result.Files
+		domains[result.Domain] = true
+		added++
+	}
+
+	var buildErr error
+	for _, d := range a.cfg.All() {
+		if !domains[d.Domain] {
+			continue
+		}
+		if err := a.checkSite(d.Domain, opts.allowBuildErrors); err != nil && buildErr == nil {
+			buildErr = err
+		}
+	}
+
+	a.printf("\nSummary: %d added, %d already registered, %d failed\n", added, existed, failed)
+
+	if a.json() {
+		if err := a.writeJSON(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d packages failed to add", failed)
+	}
+	return buildErr
+}
@@ -0,0 +1,409 @@
+// Package cli implements the vanity command line: one binary whose
+// subcommands share the global flags, configuration loading, package lookup,
+// the repository lock and site validation. The former standalone binaries,
+// add-package, update-packages and generate-llms-txt, run the same commands
+// through Shim.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/lock"
+	"go.ngs.io/internal/site"
+)
+
+// Output formats of --output.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// runFunc runs a command with its positional arguments.
+type runFunc func(a *app, args []string) error
+
+// command is a subcommand. setup registers the command's flags and returns
+// the function running it; commands with subcommands have no setup.
+type command struct {
+	name        string
+	summary     string
+	usage       string // Arguments after the command name
+	description string
+	examples    []string
+	setup       func(fs *pflag.FlagSet) runFunc
+	subcommands []*command
+}
+
+// app is the state shared by the commands of one invocation.
+type app struct {
+	name       string // Command line used in usage, e.g. "vanity add"
+	configFile string
+	contentDir string
+	output     string
+	builder    string // Site builder of validation: hugo or native
+	verbose    bool
+
+	cfg *config.Config
+	out io.Writer // Results: JSON or text
+	log io.Writer // Progress; stderr when the results are JSON
+}
+
+// errUsage reports invalid arguments after the usage has been printed.
+var errUsage = errors.New("invalid arguments")
+
+// exitError ends the command with a specific status without a message.
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func commands() []*command {
+	return []*command{
+		addCommand(),
+		updateCommand(),
+		removeCommand(),
+		renameCommand(),
+		listCommand(),
+		showCommand(),
+		validateCommand(),
+		doctorCommand(),
+		depsCommand(),
+		{
+			name:        "generate",
+			summary:     "Generate files from package data",
+			subcommands: []*command{llmsCommand(), feedsCommand(), docsCommand()},
+		},
+		buildCommand(),
+		serveCommand(),
+	}
+}
+
+// Main runs the vanity command with the process arguments and exits.
+func Main() {
+	os.Exit(Run("vanity", os.Args[1:]))
+}
+
+// Shim runs the command at path (e.g. "generate", "llms") under the name of
+// a former binary and exits.
+func Shim(name string, args []string, path ...string) {
+	cmd := find(commands(), path)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command: %s\n", strings.Join(path, " "))
+		os.Exit(1)
+	}
+	os.Exit(execute(name, cmd, args))
+}
+
+// FileFlag rewrites the -o/--output flag of a former binary, which named an
+// output file or directory, to the flag of the vanity command, e.g. -f/--file;
+// vanity uses --output for the result format.
+func FileFlag(args []string, short, long string) []string {
+	args = append([]string{}, args...)
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return args
+		case arg == "-o" || arg == "--output":
+			args[i] = long
+		case strings.HasPrefix(arg, "--output="):
+			args[i] = long + "=" + strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-o"):
+			args[i] = short + strings.TrimPrefix(arg, "-o")
+		}
+	}
+	return args
+}
+
+// Run runs the vanity command line and returns the exit status.
+func Run(name string, args []string) int {
+	cmds := commands()
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd := find(cmds, args[:1])
+		if cmd == nil {
+			fmt.Fprintf(os.Stderr, "Error: unknown command: %s\n\n", args[0])
+			printCommands(os.Stderr, name, cmds)
+			return 1
+		}
+		name += " " + cmd.name
+		args = args[1:]
+		if cmd.subcommands == nil {
+			return execute(name, cmd, args)
+		}
+		cmds = cmd.subcommands
+	}
+
+	printCommands(os.Stdout, name, cmds)
+	return 0
+}
+
+func find(cmds []*command, path []string) *command {
+	for _, cmd := range cmds {
+		if cmd.name != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return cmd
+		}
+		return find(cmd.subcommands, path[1:])
+	}
+	return nil
+}
+
+func printCommands(w io.Writer, name string, cmds []*command) {
+	fmt.Fprintf(w, "Usage: %s <command> [options]\n", name)
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> --help' for the options of a command.\n", name)
+}
+
+// execute parses the flags of cmd and runs it.
+func execute(name string, cmd *command, args []string) int {
+	a := &app{name: name, out: os.Stdout, log: os.Stdout}
+
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.SortFlags = false
+	run := cmd.setup(fs)
+	fs.StringVar(&a.configFile, "config", config.DefaultFile, "Project configuration file")
+	fs.StringVar(&a.contentDir, "content-dir", "", "Content directory of the primary domain (default: from the configuration file)")
+	fs.StringVar(&a.output, "output", OutputText, "Output format of the results: text or json")
+	fs.StringVar(&a.builder, "builder", site.BuilderHugo, "Site builder used for validation: hugo or native (content files only, without Hugo)")
+	fs.BoolVarP(&a.verbose, "verbose", "v", false, "Print additional details")
+	help := fs.BoolP("help", "h", false, "Show help message")
+	fs.Usage = func() { printUsage(os.Stderr, name, cmd, fs) }
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *help {
+		printUsage(os.Stdout, name, cmd, fs)
+		return 0
+	}
+
+	err := a.init()
+	if err == nil {
+		err = run(a, fs.Args())
+	}
+
+	var exit exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.Is(err, errUsage):
+		printUsage(os.Stderr, name, cmd, fs)
+		return 1
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+}
+
+func printUsage(w io.Writer, name string, cmd *command, fs *pflag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s %s\n", name, cmd.usage)
+	fmt.Fprintf(w, "\n%s\n", cmd.summary)
+	if cmd.description != "" {
+		fmt.Fprintf(w, "\n%s\n", cmd.description)
+	}
+	fmt.Fprintln(w, "\nOptions:")
+	fmt.Fprint(w, fs.FlagUsages())
+	if len(cmd.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, example := range cmd.examples {
+			fmt.Fprintf(w, "  %s %s\n", name, example)
+		}
+	}
+}
+
+// init applies the global flags.
+func (a *app) init() error {
+	switch a.output {
+	case OutputText:
+	case OutputJSON:
+		a.log = os.Stderr
+	default:
+		return fmt.Errorf("unknown output format: %s (available: text, json)", a.output)
+	}
+	if _, err := site.NewBuilder(a.builder); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(a.configFile)
+	if err != nil {
+		return err
+	}
+	if a.contentDir != "" {
+		cfg.ContentDir = a.contentDir
+	}
+	a.cfg = cfg
+
+	for _, d := range cfg.All() {
+		a.debugf("Domain %s: content %s, static %s, output %s\n", d.Domain, d.ContentDir, d.StaticDir, d.OutputDir)
+	}
+	return nil
+}
+
+func (a *app) json() bool {
+	return a.output == OutputJSON
+}
+
+// printf prints progress messages.
+func (a *app) printf(format string, args ...interface{}) {
+	fmt.Fprintf(a.log, format, args...)
+}
+
+// println prints a progress line.
+func (a *app) println(args ...interface{}) {
+	fmt.Fprintln(a.log, args...)
+}
+
+// debugf prints progress messages with --verbose.
+func (a *app) debugf(format string, args ...interface{}) {
+	if a.verbose {
+		fmt.Fprintf(a.log, format, args...)
+	}
+}
+
+// writeJSON prints v as the JSON result of the command.
+func (a *app) writeJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	_, err = fmt.Fprintf(a.out, "%s\n", data)
+	return err
+}
+
+// packages lists the package pages of all domains, rejecting duplicate
+// import paths. Unreadable pages are listed with their error; commands
+// reading them report them and continue.
+func (a *app) packages() ([]hugo.PackageFile, error) {
+	files, err := hugo.ListDomainPackages(a.cfg.ContentDomains())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packages: %w", err)
+	}
+	return files, nil
+}
+
+// findPackage returns the page of the named package. With an empty domain,
+// the name must be unique across domains.
+func (a *app) findPackage(name, domain string) (hugo.PackageFile, error) {
+	files, err := a.packages()
+	if err != nil {
+		return hugo.PackageFile{}, err
+	}
+
+	var found []hugo.PackageFile
+	for _, f := range files {
+		if packageName(f) == name && (domain == "" || f.Domain == domain) {
+			found = append(found, f)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return hugo.PackageFile{}, fmt.Errorf("package not found: %s", name)
+	case 1:
+		return found[0], nil
+	default:
+		return hugo.PackageFile{}, fmt.Errorf("package %s exists in several domains; select one with --domain", name)
+	}
+}
+
+// displayName names a package in results, prefixing packages of secondary
+// domains with their domain.
+func (a *app) displayName(f hugo.PackageFile) string {
+	if f.Domain != a.cfg.Domain {
+		return f.Domain + "/" + packageName(f)
+	}
+	return packageName(f)
+}
+
+// packageName returns the name of a package: its page file name.
+func packageName(f hugo.PackageFile) string {
+	return strings.TrimSuffix(filepath.Base(f.Path), ".md")
+}
+
+// lockRepository takes the repository lock held by the commands that write
+// content files, failing if another command holds it. The returned function
+// releases it.
+func (a *app) lockRepository() (release func(), err error) {
+	l, err := lock.Acquire(lock.Path(a.configFile))
+	if err != nil {
+		return nil, err
+	}
+	a.debugf("Locked %s\n", lock.Path(a.configFile))
+	return func() {
+		if err := l.Release(); err != nil {
+			a.printf("Warning: %v\n", err)
+		}
+	}, nil
+}
+
+// validateSite builds the site of a domain into a temporary directory, so
+// the output directory is left alone. A failed build returns
+// site.BuildErrors.
+func (a *app) validateSite(domain string) error {
+	builder, err := site.NewBuilder(a.builder)
+	if err != nil {
+		return err
+	}
+	return builder.Build(a.cfg, domain, site.BuildOptions{
+		Temporary: true,
+		Args:      []string{"--minify"},
+		Log:       a.buildLog(),
+	})
+}
+
+// buildLog receives the output of site builds, which is only shown with
+// --verbose.
+func (a *app) buildLog() io.Writer {
+	if a.verbose {
+		return a.log
+	}
+	return io.Discard
+}
+
+// checkSite validates the site build of a domain after its content changed
+// and prints each build error. A failed build fails the command unless
+// allowErrors is set.
+func (a *app) checkSite(domain string, allowErrors bool) error {
+	a.printf("Validating site build of %s...\n", domain)
+	err := a.validateSite(domain)
+	if err == nil {
+		a.println("✓ Site builds successfully")
+		return nil
+	}
+
+	for _, buildErr := range buildErrors(err) {
+		a.printf("✗ %v\n", buildErr)
+	}
+	if allowErrors {
+		a.println("Warning: Site build validation failed")
+		return nil
+	}
+	return fmt.Errorf("site build of %s failed; fix the errors above or pass --allow-build-errors", domain)
+}
+
+// buildErrors returns the build errors of a failed site validation; other
+// failures, such as a missing hugo command, become a single error.
+func buildErrors(err error) site.BuildErrors {
+	var errs site.BuildErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return site.BuildErrors{{Message: err.Error()}}
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-o", "public"}, []string{"--dir", "public"}},
+		{[]string{"--output", "public"}, []string{"--dir", "public"}},
+		{[]string{"--output=public", "--limit", "20"}, []string{"--dir=public", "--limit", "20"}},
+		{[]string{"-opublic"}, []string{"-dpublic"}},
+		{[]string{"freecal", "--domain", "go.example.com"}, []string{"freecal", "--domain", "go.example.com"}},
+		// Arguments after -- belong to another command
+		{[]string{"-o", "public", "--", "-o", "x"}, []string{"--dir", "public", "--", "-o", "x"}},
+	}
+	for _, tt := range tests {
+		if got := FileFlag(tt.args, "-d", "--dir"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FileFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/depgraph"
+	"go.ngs.io/internal/hugo"
+)
+
+// depsResult is a dependency edge in the JSON result of deps.
+type depsResult struct {
+	Dependency string `json:"dependency"`
+	Latest     string `json:"latest"`
+	Dependent  string `json:"dependent"`
+	Required   string `json:"required"`
+	Behind     bool   `json:"behind"`
+}
+
+func depsCommand() *command {
+	return &command{
+		name:    "deps",
+		summary: "List dependents requiring an older version of a registry module",
+		usage:   "[package-names...] [options]",
+		description: "Lists packages requiring other modules of the registry at an older version than their latest.\n" +
+			"If no package names are provided, dependents of all packages are checked.\n" +
+			"Packages of all configured domains form one dependency graph.\n" +
+			"Exits with status 2 when a dependent lags behind.",
+		examples: []string{
+			"                           # Lagging dependents of all packages",
+			"jplaw-xml                  # Lagging dependents of jplaw-xml",
+			"--all                      # The whole dependency graph",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var all bool
+			fs.BoolVar(&all, "all", false, "List all dependents, not only those behind the latest version")
+
+			return func(a *app, args []string) error {
+				return a.listDependents(args, all)
+			}
+		},
+	}
+}
+
+// listDependents prints the dependents of the named packages, failing with
+// status 2 when one of them lags behind.
+func (a *app) listDependents(names []string, all bool) error {
+	packageFiles, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	packages := make([]*hugo.Package, 0, len(packageFiles))
+	requested := map[string]bool{}
+	for _, f := range packageFiles {
+		pkg, err := hugo.ReadPackage(f.Path)
+		if err != nil {
+			a.printf("Warning: failed to read %s: %v\n", f.Path, err)
+			continue
+		}
+		packages = append(packages, pkg)
+
+		for _, name := range names {
+			if packageName(f) == name {
+				requested[pkg.ImportPath] = true
+			}
+		}
+	}
+	if len(names) > 0 && len(requested) == 0 {
+		return fmt.Errorf("no matching packages found")
+	}
+
+	results := []depsResult{}
+	lagging := 0
+	for _, edge := range depgraph.Build(packages).Edges {
+		if len(requested) > 0 && !requested[edge.Dependency.ImportPath] {
+			continue
+		}
+		if edge.Lagging() {
+			lagging++
+		} else if !all {
+			continue
+		}
+		results = append(results, depsResult{
+			Dependency: edge.Dependency.ImportPath,
+			Latest:     edge.Dependency.Version,
+			Dependent:  edge.Dependent.ImportPath,
+			Required:   edge.Required,
+			Behind:     edge.Lagging(),
+		})
+	}
+
+	if a.json() {
+		if err := a.writeJSON(results); err != nil {
+			return err
+		}
+	} else if err := a.printDependents(results, all, lagging); err != nil {
+		return err
+	}
+
+	if lagging > 0 {
+		return exitError{code: 2}
+	}
+	return nil
+}
+
+func (a *app) printDependents(results []depsResult, all bool, lagging int) error {
+	if len(results) == 0 {
+		if all {
+			a.println("No dependents found")
+		} else {
+			a.println("✓ All dependents require the latest version")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPENDENCY\tLATEST\tDEPENDENT\tREQUIRED\tSTATUS")
+	for _, r := range results {
+		status := ""
+		if r.Behind {
+			status = "behind"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Dependency, r.Latest, r.Dependent, r.Required, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	a.printf("\n%d dependents, %d behind the latest version\n", len(results), lagging)
+	return nil
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/godoc"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/source"
+)
+
+type docsOptions struct {
+	outputDir string
+	source    string
+	cacheDir  string
+	domain    string
+}
+
+func docsCommand() *command {
+	return &command{
+		name:    "docs",
+		summary: "Generate API documentation pages from module source",
+		usage:   "[package-names...] [options]",
+		description: "Each module is read at its stored version.\n" +
+			"If no package names are provided, documentation of all packages is generated.\n" +
+			"Retired packages and modules without Go packages are skipped.",
+		examples: []string{
+			"                           # Write content/docs/<package>.html",
+			"freecal servedir           # Generate specific packages",
+			"--source github            # Read sources through the GitHub API",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var opts docsOptions
+			fs.StringVarP(&opts.outputDir, "dir", "d", "", "Output directory for the documentation pages (default: <content_dir>/docs of each domain)")
+			fs.StringVar(&opts.source, "source", source.NameGit, "Metadata source: github or git (local bare mirrors)")
+			fs.StringVar(&opts.cacheDir, "cache-dir", "", "Directory for git mirrors (default: user cache directory)")
+			fs.StringVar(&opts.domain, "domain", "", "Generate only the packages of this domain (default: all domains)")
+
+			return func(a *app, args []string) error {
+				// Documentation pages are written next to the package pages
+				release, err := a.lockRepository()
+				if err != nil {
+					return err
+				}
+				defer release()
+				return a.generateDocs(args, opts)
+			}
+		},
+	}
+}
+
+func (a *app) generateDocs(names []string, opts docsOptions) error {
+	domains, err := a.cfg.Select(opts.domain)
+	if err != nil {
+		return err
+	}
+	if opts.outputDir != "" && len(domains) > 1 {
+		return fmt.Errorf("--dir requires --domain when several domains are configured")
+	}
+	src, err := source.New(opts.source, opts.cacheDir)
+	if err != nil {
+		return err
+	}
+
+	packageFiles, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	outputDirs := map[string]string{}
+	for _, d := range domains {
+		outputDirs[d.Domain] = opts.outputDir
+		if opts.outputDir == "" {
+			outputDirs[d.Domain] = filepath.Join(d.ContentDir, hugo.DocsSection)
+		}
+	}
+
+	requested := map[string]bool{}
+	for _, name := range names {
+		requested[name] = true
+	}
+	found := map[string]bool{}
+
+	generated, failed := 0, 0
+	for _, f := range packageFiles {
+		docsDir, selected := outputDirs[f.Domain]
+		if !selected {
+			continue
+		}
+		name := packageName(f)
+		if len(requested) > 0 && !requested[name] {
+			continue
+		}
+		found[name] = true
+
+		pkg, err := hugo.ReadPackage(f.Path)
+		if err != nil {
+			a.printf("Warning: failed to read %s: %v\n", f.Path, err)
+			failed++
+			continue
+		}
+		if pkg.RepoURL == "" || pkg.ImportPath == "" || pkg.Retired {
+			continue
+		}
+
+		ok, err := generatePackageDocs(src, docsDir, name, pkg)
+		if err != nil {
+			a.printf("✗ %s - %v\n", name, err)
+			failed++
+			continue
+		}
+		if !ok {
+			a.printf("○ %s - no Go packages\n", name)
+			continue
+		}
+		a.printf("✓ %s\n", name)
+		generated++
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			a.printf("Warning: package not found: %s\n", name)
+		}
+	}
+
+	a.printf("\nGenerated documentation of %d packages\n", generated)
+	if failed > 0 {
+		return fmt.Errorf("%d packages failed", failed)
+	}
+	return nil
+}
+
+// generatePackageDocs writes the documentation page of a package. It
+// reports false when the module has no documentable Go packages.
+func generatePackageDocs(src source.Source, outputDir, name string, pkg *hugo.Package) (bool, error) {
+	module, err := godoc.Load(src, pkg.RepoURL, pkg.Version, pkg.ImportPath)
+	if err != nil {
+		return false, err
+	}
+	if len(module.Packages) == 0 {
+		return false, nil
+	}
+
+	var body bytes.Buffer
+	if err := module.Render(&body); err != nil {
+		return false, err
+	}
+
+	page := &hugo.DocsPage{
+		Title:   pkg.Title + " API documentation",
+		Package: name,
+		Module:  pkg.ImportPath,
+		Version: pkg.Version,
+		RepoURL: pkg.RepoURL,
+		Body:    body.String(),
+	}
+	if err := hugo.WriteDocsPage(filepath.Join(outputDir, name+".html"), page); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/doctor"
+	"go.ngs.io/internal/hugo"
+)
+
+func doctorCommand() *command {
+	var rules []string
+	for _, r := range doctor.Rules {
+		fixable := ""
+		if r.Fixable {
+			fixable = ", fixable"
+		}
+		rules = append(rules, fmt.Sprintf("  %-22s %s (%s%s)", r.ID, r.Description, r.Severity, fixable))
+	}
+
+	return &command{
+		name:    "doctor",
+		summary: "Check package pages for consistency",
+		usage:   "[options]",
+		description: "The package pages of all domains are checked against these rules:\n" + strings.Join(rules, "\n") + "\n" +
+			"The command exits with status 2 if errors remain, or warnings with --strict.",
+		examples: []string{
+			"                          # Report problems",
+			"--fix                     # Fix the fixable problems",
+			"--sarif doctor.sarif      # Also write a SARIF log for code scanning",
+			"--output json --strict",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var opts doctorOptions
+			fs.BoolVar(&opts.fix, "fix", false, "Fix the fixable problems in place")
+			fs.BoolVar(&opts.strict, "strict", false, "Exit with status 2 on warnings too")
+			fs.StringVar(&opts.sarif, "sarif", "", "Write the findings as a SARIF log to this file")
+
+			return func(a *app, args []string) error {
+				if len(args) > 0 {
+					return errUsage
+				}
+				if opts.fix {
+					release, err := a.lockRepository()
+					if err != nil {
+						return err
+					}
+					defer release()
+				}
+				return a.doctor(opts)
+			}
+		},
+	}
+}
+
+type doctorOptions struct {
+	fix    bool
+	strict bool
+	sarif  string
+}
+
+func (a *app) doctor(opts doctorOptions) error {
+	pages, err := doctor.Load(a.cfg.ContentDomains())
+	if err != nil {
+		return err
+	}
+
+	// The domain serving an import path, or none
+	domainOf := func(importPath string) string {
+		d := a.cfg.DomainOf(importPath)
+		if strings.HasPrefix(importPath, d.Domain+"/") {
+			return d.Domain
+		}
+		return ""
+	}
+
+	var fixed []doctor.Finding
+	if opts.fix {
+		for _, p := range pages {
+			rules := doctor.Fix(p)
+			if len(rules) == 0 {
+				continue
+			}
+			if err := hugo.WritePackage(p.Path, p.Package); err != nil {
+				return fmt.Errorf("failed to write %s: %w", p.Path, err)
+			}
+			for _, rule := range rules {
+				fixed = append(fixed, doctor.Finding{
+					Rule:     rule,
+					Severity: doctor.LookupRule(rule).Severity,
+					File:     p.Path,
+					Message:  "fixed",
+					Fixed:    true,
+				})
+			}
+			a.printf("✓ Fixed %s: %s\n", p.Path, strings.Join(rules, ", "))
+		}
+	}
+
+	findings := doctor.Check(pages, domainOf)
+	errors, warnings, fixable := 0, 0, 0
+	for _, f := range findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		a.printf("%s: %s: %s (%s)\n", location, f.Severity, f.Message, f.Rule)
+		if f.Severity == doctor.Error {
+			errors++
+		} else {
+			warnings++
+		}
+		if doctor.LookupRule(f.Rule).Fixable {
+			fixable++
+		}
+	}
+
+	failed := errors > 0 || opts.strict && warnings > 0
+	mark := "✓"
+	if failed {
+		mark = "✗"
+	}
+	switch {
+	case len(findings) == 0:
+		a.printf("✓ %d packages checked, no problems found\n", len(pages))
+	case fixable > 0 && !opts.fix:
+		a.printf("%s %d packages checked: %d errors, %d warnings (%d may be fixed with --fix)\n", mark, len(pages), errors, warnings, fixable)
+	default:
+		a.printf("%s %d packages checked: %d errors, %d warnings\n", mark, len(pages), errors, warnings)
+	}
+
+	if opts.sarif != "" {
+		if err := writeSARIF(opts.sarif, findings); err != nil {
+			return err
+		}
+		a.printf("Wrote %s\n", opts.sarif)
+	}
+	if a.json() {
+		if err := a.writeJSON(append(fixed, findings...)); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return exitError{code: 2}
+	}
+	return nil
+}
+
+// writeSARIF writes findings as a SARIF 2.1.0 log, the format code scanning
+// services such as GitHub's read.
+func writeSARIF(path string, findings []doctor.Finding) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type region struct {
+		StartLine int `json:"startLine"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *region `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+	type rule struct {
+		ID                   string  `json:"id"`
+		ShortDescription     message `json:"shortDescription"`
+		DefaultConfiguration struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+	}
+
+	var rules []rule
+	for _, r := range doctor.Rules {
+		sarifRule := rule{ID: r.ID, ShortDescription: message{r.Description}}
+		sarifRule.DefaultConfiguration.Level = string(r.Severity)
+		rules = append(rules, sarifRule)
+	}
+
+	results := []result{}
+	for _, f := range findings {
+		var loc location
+		loc.PhysicalLocation.ArtifactLocation.URI = f.File
+		if f.Line > 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: f.Line}
+		}
+		results = append(results, result{
+			RuleID:    f.Rule,
+			Level:     string(f.Severity),
+			Message:   message{f.Message},
+			Locations: []location{loc},
+		})
+	}
+
+	log := map[string]interface{}{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":  "vanity doctor",
+						"rules": rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode SARIF log: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ngs.io/internal/config"
+)
+
+func newTestApp(t *testing.T, pages map[string]string) (*app, *bytes.Buffer) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.ContentDir = t.TempDir()
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(cfg.ContentDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	return &app{name: "vanity", cfg: cfg, out: &out, log: &out}, &out
+}
+
+func TestDoctorSummary(t *testing.T) {
+	// A custom documentation site is fine; the license is only a warning
+	widget := "---\ntitle: widget\nimport_path: go.ngs.io/widget\nrepo_url: https://github.com/ngs/widget\n" +
+		"documentation_url: https://widget.example.com\nlicense: mit\n---\n"
+
+	tests := []struct {
+		name    string
+		strict  bool
+		summary string
+		code    int
+	}{
+		{"warnings", false, "✓ 1 packages checked: 0 errors, 1 warnings (1 may be fixed with --fix)", 0},
+		{"strict", true, "✗ 1 packages checked: 0 errors, 1 warnings (1 may be fixed with --fix)", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, out := newTestApp(t, map[string]string{"widget.md": widget})
+			err := a.doctor(doctorOptions{strict: tt.strict})
+			code := 0
+			if exit, ok := err.(exitError); ok {
+				code = exit.code
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.code {
+				t.Errorf("exit status %d, want %d", code, tt.code)
+			}
+			if !strings.Contains(out.String(), tt.summary+"\n") {
+				t.Errorf("output lacks %q:\n%s", tt.summary, out.String())
+			}
+		})
+	}
+}
+
+func TestDoctorUnreadableLine(t *testing.T) {
+	a, out := newTestApp(t, map[string]string{
+		"broken.md": "---\ntitle: broken\nimport_path: go.ngs.io/broken\ndescription: a: b\n---\n",
+	})
+	sarif := filepath.Join(t.TempDir(), "doctor.sarif")
+	if err := a.doctor(doctorOptions{sarif: sarif}); err != (exitError{code: 2}) {
+		t.Fatalf("doctor = %v, want exit status 2", err)
+	}
+
+	file := filepath.Join(a.cfg.ContentDir, "broken.md")
+	if want := file + ":4: error: failed to parse frontmatter: mapping values are not allowed in this context (unreadable)\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("output lacks %q:\n%s", want, out.String())
+	}
+
+	data, err := os.ReadFile(sarif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	location := log.Runs[0].Results[0].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != file || location.Region.StartLine != 4 {
+		t.Errorf("SARIF location = %+v, want %s line 4", location, file)
+	}
+}
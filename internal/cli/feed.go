@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Authors     []jsonAuthor   `json:"authors"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentText   string   `json:"content_text"`
+	DatePublished string   `json:"date_published"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// lastUpdated returns the date of the newest entry, or now when there are
+// no entries. Entries are sorted newest first.
+func lastUpdated(entries []feedEntry) time.Time {
+	if len(entries) == 0 {
+		return time.Now().UTC()
+	}
+	return entries[0].Release.Date
+}
+
+func writeAtom(path string, ch feedChannel, entries []feedEntry) error {
+	feed := atomFeed{
+		Title:   ch.Title,
+		ID:      ch.BaseURL,
+		Updated: lastUpdated(entries).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: ch.BaseURL},
+			{Href: ch.BaseURL + "atom.xml", Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: atomAuthor{Name: ch.Author},
+	}
+	for _, e := range entries {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   e.Title,
+			ID:      e.ID,
+			Updated: e.Release.Date.Format(time.RFC3339),
+			Link:    atomLink{Href: e.URL},
+			Summary: e.Summary,
+		})
+	}
+
+	return writeXML(path, feed)
+}
+
+func writeRSS(path string, ch feedChannel, entries []feedEntry) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         ch.Title,
+			Link:          ch.BaseURL,
+			Description:   ch.Description,
+			LastBuildDate: lastUpdated(entries).Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			Description: e.Summary,
+			GUID:        rssGUID{Value: e.ID},
+			PubDate:     e.Release.Date.Format(time.RFC1123Z),
+		})
+	}
+
+	return writeXML(path, feed)
+}
+
+func writeJSONFeed(path string, ch feedChannel, entries []feedEntry) error {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       ch.Title,
+		HomePageURL: ch.BaseURL,
+		FeedURL:     ch.BaseURL + "feed.json",
+		Description: ch.Description,
+		Authors:     []jsonAuthor{{Name: ch.Author}},
+		Items:       []jsonFeedItem{},
+	}
+	for _, e := range entries {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            e.ID,
+			URL:           e.URL,
+			Title:         e.Title,
+			ContentText:   e.Summary,
+			DatePublished: e.Release.Date.Format(time.RFC3339),
+			Tags:          []string{e.Release.Event},
+		})
+	}
+
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func writeXML(path string, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	content := append([]byte(xml.Header), data...)
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+)
+
+// feedChannel describes the site publishing the feeds.
+type feedChannel struct {
+	BaseURL     string
+	Domain      string
+	Title       string
+	Description string
+	Author      string
+}
+
+func newFeedChannel(d *config.Config, baseURL string) feedChannel {
+	return feedChannel{
+		BaseURL:     strings.TrimSuffix(baseURL, "/") + "/",
+		Domain:      d.Domain,
+		Title:       d.Domain + " releases",
+		Description: "New packages and releases of Go modules hosted at " + d.Domain,
+		Author:      d.Site.Author,
+	}
+}
+
+// feedEntry is a feed item shared by all output formats.
+type feedEntry struct {
+	ID      string
+	Title   string
+	Summary string
+	URL     string
+	Release hugo.Release
+}
+
+type feedWriter struct {
+	filename string
+	write    func(path string, ch feedChannel, entries []feedEntry) error
+}
+
+var feedWriters = []feedWriter{
+	{filename: "atom.xml", write: writeAtom},
+	{filename: "rss.xml", write: writeRSS},
+	{filename: "feed.json", write: writeJSONFeed},
+}
+
+func feedsCommand() *command {
+	return &command{
+		name:    "feeds",
+		summary: "Generate Atom, RSS and JSON feeds of package releases",
+		usage:   "[options]",
+		examples: []string{
+			"                           # Write feeds to the output directory of the domain",
+			"-d dist                    # Write feeds to dist/",
+			"--limit 20                 # Keep only the 20 latest entries",
+			"--domain go.example.com",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var (
+				outputDir string
+				baseURL   string
+				limit     int
+				domain    string
+			)
+			fs.StringVarP(&outputDir, "dir", "d", "", "Output directory for the feed files (default: <output_dir> of the domain)")
+			fs.StringVar(&baseURL, "base-url", "", "Base URL of the site (default: https://<domain>/)")
+			fs.IntVar(&limit, "limit", 50, "Maximum number of entries per feed")
+			fs.StringVar(&domain, "domain", "", "Domain whose releases to publish (default: primary domain)")
+
+			return func(a *app, args []string) error {
+				if len(args) > 0 {
+					return errUsage
+				}
+				d, err := a.cfg.Lookup(domain)
+				if err != nil {
+					return err
+				}
+				if outputDir == "" {
+					outputDir = d.OutputDir
+				}
+				if baseURL == "" {
+					baseURL = d.BaseURL()
+				}
+				return a.generateFeeds(d.ReleasesPath(), outputDir, newFeedChannel(d, baseURL), limit)
+			}
+		},
+	}
+}
+
+func (a *app) generateFeeds(releasesPath, outputDir string, ch feedChannel, limit int) error {
+	all, err := hugo.ReadReleases(releasesPath)
+	if err != nil {
+		return fmt.Errorf("failed to read releases: %w", err)
+	}
+
+	// Keep the releases of the domain; the history of the primary domain
+	// predates data_dir and may hold those of the others
+	var releases []hugo.Release
+	for _, release := range all {
+		if a.cfg.DomainOf(release.ImportPath).Domain == ch.Domain {
+			releases = append(releases, release)
+		}
+	}
+
+	// Newest first
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Date.After(releases[j].Date)
+	})
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+
+	entries := make([]feedEntry, 0, len(releases))
+	for _, release := range releases {
+		entries = append(entries, newFeedEntry(ch, release))
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for _, w := range feedWriters {
+		path := filepath.Join(outputDir, w.filename)
+		if err := w.write(path, ch, entries); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		a.printf("Generated %s\n", path)
+	}
+
+	return nil
+}
+
+// feedEntryID identifies an entry by the import path and version it
+// announces, which stay the same when the package page is renamed.
+func feedEntryID(ch feedChannel, release hugo.Release) string {
+	id := fmt.Sprintf("%s#%s-%s", ch.BaseURL, release.Event, release.ImportPath)
+	if release.Version != "" {
+		id += "@" + release.Version
+	}
+	return id
+}
+
+func newFeedEntry(ch feedChannel, release hugo.Release) feedEntry {
+	e := feedEntry{
+		ID:      feedEntryID(ch, release),
+		URL:     release.URL,
+		Release: release,
+	}
+	if e.URL == "" {
+		e.URL = ch.BaseURL + release.Name + "/"
+	}
+
+	switch release.Event {
+	case hugo.EventAdded:
+		e.Title = fmt.Sprintf("New package: %s", release.ImportPath)
+		e.Summary = fmt.Sprintf("%s is now available at %s.", release.ImportPath, ch.Domain)
+		if release.Version != "" {
+			e.Summary = fmt.Sprintf("%s %s is now available at %s.", release.ImportPath, release.Version, ch.Domain)
+		}
+	default:
+		e.Title = fmt.Sprintf("%s %s", release.ImportPath, release.Version)
+		if release.PreviousVersion != "" {
+			e.Summary = fmt.Sprintf("%s was updated from %s to %s.", release.ImportPath, release.PreviousVersion, release.Version)
+		} else {
+			e.Summary = fmt.Sprintf("%s %s was released.", release.ImportPath, release.Version)
+		}
+	}
+
+	return e
+}
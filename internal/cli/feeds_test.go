@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files of testdata")
+
+func testReleases() []hugo.Release {
+	return []hugo.Release{
+		{Event: hugo.EventAdded, Name: "widget", ImportPath: "go.ngs.io/widget", Version: "v1.0.0", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Event: hugo.EventRelease, Name: "widget", ImportPath: "go.ngs.io/widget", Version: "v1.1.0", PreviousVersion: "v1.0.0",
+			URL: "https://github.com/ngs/widget/releases/tag/v1.1.0", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Event: hugo.EventAdded, Name: "gadget", ImportPath: "go.ngs.io/gadget", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Event: hugo.EventRelease, Name: "tool", ImportPath: "go.ngs.io/tool/v2", Version: "v2.0.0", Date: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		// Releases of other domains are left out
+		{Event: hugo.EventAdded, Name: "other", ImportPath: "go.example.com/other", Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+// generateTestFeeds writes the feeds of releases for go.ngs.io and returns
+// the output directory.
+func generateTestFeeds(t *testing.T, releases []hugo.Release, limit int) string {
+	t.Helper()
+	a, _ := newTestApp(t, nil)
+	a.cfg.Domains = []*config.Config{{Domain: "go.example.com"}}
+	releasesPath := filepath.Join(t.TempDir(), hugo.ReleasesFile)
+	if err := hugo.WriteReleases(releasesPath, releases); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+	if err := a.generateFeeds(releasesPath, outputDir, newFeedChannel(a.cfg, "https://go.ngs.io"), limit); err != nil {
+		t.Fatal(err)
+	}
+	return outputDir
+}
+
+// TestFeedsGolden compares the feeds with testdata/feeds. Run with -update to
+// rewrite the golden files.
+func TestFeedsGolden(t *testing.T) {
+	outputDir := generateTestFeeds(t, testReleases(), 3)
+	for _, w := range feedWriters {
+		t.Run(w.filename, func(t *testing.T) {
+			got, err := os.ReadFile(filepath.Join(outputDir, w.filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "feeds", w.filename)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("%s differs from %s:\n%s", w.filename, golden, got)
+			}
+		})
+	}
+}
+
+func TestFeedsAfterRename(t *testing.T) {
+	before := generateTestFeeds(t, testReleases(), 0)
+
+	releasesPath := filepath.Join(t.TempDir(), hugo.ReleasesFile)
+	if err := hugo.WriteReleases(releasesPath, testReleases()); err != nil {
+		t.Fatal(err)
+	}
+	inNgs := func(importPath string) bool { return importPath != "go.example.com/other" }
+	if err := hugo.RenameReleases(releasesPath, "widget", "gizmo", inNgs); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := hugo.ReadReleases(releasesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := generateTestFeeds(t, renamed, 0)
+
+	ch := feedChannel{BaseURL: "https://go.ngs.io/", Domain: "go.ngs.io"}
+	for i, release := range renamed {
+		if got, want := feedEntryID(ch, release), feedEntryID(ch, testReleases()[i]); got != want {
+			t.Errorf("entry ID after rename = %s, want %s", got, want)
+		}
+	}
+	if renamed[0].Name != "gizmo" || renamed[0].ImportPath != "go.ngs.io/widget" || renamed[2].Name != "gadget" {
+		t.Errorf("renamed releases = %+v", renamed)
+	}
+
+	atomBefore, err := os.ReadFile(filepath.Join(before, "atom.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomAfter, err := os.ReadFile(filepath.Join(after, "atom.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the link to the page of the added package moves
+	want := strings.Replace(string(atomBefore), "https://go.ngs.io/widget/", "https://go.ngs.io/gizmo/", 1)
+	if string(atomAfter) != want {
+		t.Errorf("atom.xml after rename =\n%s\nwant\n%s", atomAfter, want)
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/hugo"
+)
+
+// listEntry is a package in the JSON result of list.
+type listEntry struct {
+	Name        string `json:"name"`
+	Domain      string `json:"domain"`
+	File        string `json:"file"`
+	ImportPath  string `json:"import_path"`
+	Version     string `json:"version,omitempty"`
+	RepoURL     string `json:"repo_url"`
+	Description string `json:"description,omitempty"`
+	Deprecated  string `json:"deprecated,omitempty"`
+}
+
+func listCommand() *command {
+	return &command{
+		name:    "list",
+		summary: "List the packages of the registry",
+		usage:   "[options]",
+		examples: []string{
+			"                           # All packages",
+			"--domain go.example.com    # Packages of one domain",
+			"--output json              # Machine-readable list",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var domain string
+			fs.StringVar(&domain, "domain", "", "List only the packages of this domain (default: all domains)")
+
+			return func(a *app, args []string) error {
+				if len(args) > 0 {
+					return errUsage
+				}
+				return a.listPackages(domain)
+			}
+		},
+	}
+}
+
+func (a *app) listPackages(domain string) error {
+	if domain != "" {
+		if _, err := a.cfg.Lookup(domain); err != nil {
+			return err
+		}
+	}
+
+	files, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	entries := []listEntry{}
+	for _, f := range files {
+		if domain != "" && f.Domain != domain {
+			continue
+		}
+		pkg, err := hugo.ReadPackage(f.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", f.Path, err)
+			continue
+		}
+		entries = append(entries, listEntry{
+			Name:        a.displayName(f),
+			Domain:      f.Domain,
+			File:        f.Path,
+			ImportPath:  pkg.ImportPath,
+			Version:     pkg.Version,
+			RepoURL:     pkg.RepoURL,
+			Description: pkg.Description,
+			Deprecated:  pkg.Deprecated,
+		})
+	}
+
+	if a.json() {
+		return a.writeJSON(entries)
+	}
+
+	// --verbose adds the page of each package
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "NAME\tVERSION\tIMPORT PATH\tREPOSITORY")
+	if a.verbose {
+		fmt.Fprint(w, "\tFILE")
+	}
+	fmt.Fprintln(w)
+	for _, e := range entries {
+		version := e.Version
+		if version == "" {
+			version = "-"
+		}
+		if e.Deprecated != "" {
+			version += " (deprecated)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s", e.Name, version, e.ImportPath, e.RepoURL)
+		if a.verbose {
+			fmt.Fprintf(w, "\t%s", e.File)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	a.debugf("\n%d packages\n", len(entries))
+	return nil
+}
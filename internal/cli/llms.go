@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+)
+
+const llmsTxtTemplate = `# {{.Site.Title}}
+
+> {{.Site.Description}}
+
+{{.Domain}} provides custom vanity import paths for Go modules developed by {{.Site.Author}}.
+You can install packages using short, memorable URLs like ` + "`{{.Domain}}/package`" + `.
+
+## Installation
+
+Add a library to your module:
+
+` + "```bash" + `
+go get {{.Domain}}/<package-name>
+` + "```" + `
+
+Install a command:
+
+` + "```bash" + `
+go install {{.Domain}}/<package-name>@latest
+` + "```" + `
+
+## Available Packages
+
+{{range .Packages}}### {{.Title}}
+{{if .Deprecated}}
+> **Deprecated**: {{.Deprecated}}
+{{end}}{{if .Description}}
+{{.Description}}
+{{end}}
+- **Import**: ` + "`{{.ImportPath}}`" + `{{if .Version}}
+- **Version**: {{.Version}}{{end}}{{if .GoVersion}}
+- **Go**: >= {{.GoVersion}}{{if .Toolchain}} (toolchain {{.Toolchain}}){{end}}{{end}}{{if .Dependencies}}
+- **Dependencies**: {{.Dependencies}} direct{{end}}{{if .License}}
+- **License**: {{.License}}{{end}}{{if .DocumentationURL}}
+- **Documentation**: {{.DocumentationURL}}{{end}}{{if .RepoURL}}
+- **Repository**: {{.RepoURL}}{{end}}
+
+` + "```bash" + `{{if .IsLibrary}}
+go get {{.ImportPath}}{{end}}{{range .Commands}}
+go install {{.}}@latest{{end}}
+` + "```" + `
+{{if $.Full}}{{with .Usage}}
+#### Usage
+
+` + "```text" + `
+{{.}}
+` + "```" + `
+{{end}}{{with .Examples}}
+#### Examples
+{{range .}}
+##### {{.Name}}
+{{with .Doc}}
+{{.}}
+{{end}}
+` + "```go" + `
+{{.Code}}
+` + "```" + `
+{{with .Output}}
+Output:
+
+` + "```text" + `
+{{.}}` + "```" + `
+{{end}}{{end}}{{end}}{{with .Body}}
+#### README
+
+{{.}}
+{{end}}{{end}}
+{{end}}`
+
+type templateData struct {
+	Domain   string
+	Site     config.Site
+	Packages []*hugo.Package
+	Full     bool // llms-full.txt: include usage, examples and READMEs
+}
+
+func llmsCommand() *command {
+	return &command{
+		name:    "llms",
+		summary: "Generate llms.txt file from package data",
+		usage:   "[options]",
+		examples: []string{
+			"                      # Output to stdout",
+			"-f llms.txt           # Output to file",
+			"-f public/llms.txt    # Output to public directory",
+			"--lang ja -f public/ja/llms.txt",
+			"--full -f public/llms-full.txt",
+			"--domain go.example.com -f domains/go.example.com/public/llms.txt",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var (
+				outputFile string
+				lang       string
+				full       bool
+				domain     string
+			)
+			fs.StringVarP(&outputFile, "file", "f", "", "Output file path (default: stdout)")
+			fs.StringVar(&lang, "lang", "", "Use localized descriptions of this language, e.g. ja")
+			fs.BoolVar(&full, "full", false, "Include usage, examples and READMEs (llms-full.txt)")
+			fs.StringVar(&domain, "domain", "", "Domain to describe (default: primary domain)")
+
+			return func(a *app, args []string) error {
+				if len(args) > 0 {
+					return errUsage
+				}
+				return a.generateLLMsTxt(domain, outputFile, lang, full)
+			}
+		},
+	}
+}
+
+func (a *app) generateLLMsTxt(domain, outputFile, lang string, full bool) error {
+	if lang != "" && !isLanguage(lang) {
+		return fmt.Errorf("unknown language: %s (available: %s)", lang, strings.Join(hugo.Languages, ", "))
+	}
+
+	d, err := a.cfg.Lookup(domain)
+	if err != nil {
+		return err
+	}
+
+	// Get list of packages; listing all domains rejects duplicate import paths
+	packageFiles, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	// Read the packages of the domain
+	packages := make([]*hugo.Package, 0, len(packageFiles))
+	for _, f := range packageFiles {
+		if f.Domain != d.Domain {
+			continue
+		}
+		pkg, err := hugo.ReadPackage(f.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", f.Path, err)
+			continue
+		}
+		if pkg.Retired {
+			continue
+		}
+		if localization, ok := pkg.Localizations[lang]; ok {
+			if localization.Description != "" {
+				pkg.Description = localization.Description
+			}
+			if localization.Body != "" {
+				pkg.Body = localization.Body
+			}
+		}
+		packages = append(packages, pkg)
+	}
+
+	// Sort packages by title
+	sort.Slice(packages, func(i, j int) bool {
+		return strings.ToLower(packages[i].Title) < strings.ToLower(packages[j].Title)
+	})
+
+	// Create template
+	tmpl, err := template.New("llms.txt").Parse(llmsTxtTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// Prepare template data
+	data := templateData{
+		Domain:   d.Domain,
+		Site:     d.Site,
+		Packages: packages,
+		Full:     full,
+	}
+
+	// Determine output destination
+	var output io.Writer = a.out
+	if outputFile != "" {
+		// Ensure directory exists
+		dir := filepath.Dir(outputFile)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	// Execute template
+	if err := tmpl.Execute(output, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	if outputFile != "" {
+		fmt.Fprintf(os.Stderr, "Generated %s\n", outputFile)
+	}
+
+	return nil
+}
+
+func isLanguage(lang string) bool {
+	for _, l := range hugo.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
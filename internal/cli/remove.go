@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/assets"
+	"go.ngs.io/internal/depgraph"
+	"go.ngs.io/internal/hugo"
+)
+
+// defaultNotice is the deprecation message of a removed package's stub.
+const defaultNotice = "This package is no longer maintained."
+
+// removeResult is the JSON result of remove.
+type removeResult struct {
+	Name    string   `json:"name"`
+	Domain  string   `json:"domain"`
+	Removed []string `json:"removed"`
+	Stub    string   `json:"stub,omitempty"` // Page kept to serve the import path
+	DryRun  bool     `json:"dry_run,omitempty"`
+}
+
+func removeCommand() *command {
+	return &command{
+		name:    "remove",
+		summary: "Remove a package from the registry",
+		usage:   "<package-name> [options]",
+		description: "The package page, its localized pages, mirrored README images and API documentation page are removed.\n" +
+			"With --stub, a retired page is kept so that the import path still resolves and shows a deprecation notice.\n" +
+			"Packages other registry modules require are only removed with --stub or --force.",
+		examples: []string{
+			"mypackage",
+			"mypackage --dry-run",
+			"mypackage --stub --notice \"Use go.ngs.io/newpackage instead.\"",
+			"mypackage --domain go.example.com",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var (
+				domain string
+				opts   removeOptions
+			)
+			fs.StringVar(&domain, "domain", "", "Domain of the package when the name exists in several domains")
+			fs.BoolVar(&opts.stub, "stub", false, "Keep a retired page serving the import path")
+			fs.StringVar(&opts.notice, "notice", defaultNotice, "Deprecation notice of the retired page")
+			fs.BoolVar(&opts.force, "force", false, "Remove the package even if other registry modules require it")
+			fs.BoolVar(&opts.dryRun, "dry-run", false, "Show what would be removed without making changes")
+			fs.BoolVar(&opts.allowBuildErrors, "allow-build-errors", false, "Only warn when the site fails to build after the change")
+
+			return func(a *app, args []string) error {
+				if len(args) != 1 {
+					return errUsage
+				}
+				if !opts.dryRun {
+					release, err := a.lockRepository()
+					if err != nil {
+						return err
+					}
+					defer release()
+				}
+				return a.removePackage(args[0], domain, opts)
+			}
+		},
+	}
+}
+
+type removeOptions struct {
+	stub   bool
+	notice string
+	force  bool
+	dryRun bool
+
+	allowBuildErrors bool
+}
+
+func (a *app) removePackage(name, domain string, opts removeOptions) error {
+	f, err := a.findPackage(name, domain)
+	if err != nil {
+		return err
+	}
+	d, err := a.cfg.Lookup(f.Domain)
+	if err != nil {
+		return err
+	}
+	pkg, err := hugo.ReadPackage(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+	if !opts.stub && !opts.force {
+		if err := a.checkDependents(pkg.ImportPath); err != nil {
+			return err
+		}
+	}
+
+	dryRun := opts.dryRun
+	result := removeResult{Name: a.displayName(f), Domain: f.Domain, Removed: []string{}, DryRun: dryRun}
+	if dryRun {
+		a.println("(DRY RUN - no changes will be made)")
+	}
+
+	// Pages first, so a failure leaves no page pointing at removed assets
+	pages := []string{f.Path}
+	for _, lang := range hugo.Languages {
+		pages = append(pages, hugo.LocalizedPath(f.Path, lang))
+	}
+	if dryRun {
+		result.Removed = append(result.Removed, existing(pages...)...)
+	} else {
+		removed, err := hugo.RemovePackage(f.Path)
+		result.Removed = append(result.Removed, removed...)
+		if err != nil {
+			return err
+		}
+	}
+
+	generated := []string{
+		assets.NewMirror(d.StaticDir).Dir(name),
+		filepath.Join(d.ContentDir, hugo.DocsSection, name+".html"),
+	}
+	for _, path := range existing(generated...) {
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		result.Removed = append(result.Removed, path)
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	for _, path := range result.Removed {
+		a.printf("%s %s\n", verb, path)
+	}
+
+	if opts.stub {
+		if !dryRun {
+			if err := hugo.WritePackage(f.Path, pkg.Stub(opts.notice)); err != nil {
+				return fmt.Errorf("failed to write stub: %w", err)
+			}
+		}
+		result.Stub = f.Path
+		a.printf("Kept stub %s: %s\n", f.Path, opts.notice)
+	}
+	a.printf("✓ %s %s (%s)\n", verb, result.Name, f.ImportPath)
+
+	if !dryRun {
+		a.println()
+		if err := a.checkSite(f.Domain, opts.allowBuildErrors); err != nil {
+			return err
+		}
+	}
+
+	if a.json() {
+		return a.writeJSON(result)
+	}
+	return nil
+}
+
+// existing returns the paths that exist, or cannot be checked.
+func existing(paths ...string) []string {
+	var found []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// checkDependents fails when other registry modules require the module at
+// importPath, since removing it would orphan their requirement.
+func (a *app) checkDependents(importPath string) error {
+	files, err := a.packages()
+	if err != nil {
+		return err
+	}
+	var pkgs []*hugo.Package
+	for _, f := range files {
+		pkg, err := hugo.ReadPackage(f.Path)
+		if err != nil {
+			a.printf("Warning: failed to read %s: %v\n", f.Path, err)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	var dependents []string
+	for _, e := range depgraph.Build(pkgs).Dependents(importPath) {
+		dependents = append(dependents, e.Dependent.ImportPath)
+	}
+	if len(dependents) > 0 {
+		return fmt.Errorf("%s is required by %s; keep its import path with --stub or use --force",
+			importPath, strings.Join(dependents, ", "))
+	}
+	return nil
+}
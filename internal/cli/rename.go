@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/assets"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/modpath"
+)
+
+type renameOptions struct {
+	importPath string
+	domain     string
+	stub       bool
+	alias      bool
+	force      bool
+	dryRun     bool
+
+	allowBuildErrors bool
+}
+
+// renameResult is the JSON result of rename.
+type renameResult struct {
+	Name          string `json:"name"`
+	NewName       string `json:"new_name"`
+	Domain        string `json:"domain"`
+	File          string `json:"file"`
+	ImportPath    string `json:"import_path"`
+	OldImportPath string `json:"old_import_path,omitempty"` // Set when the import path changed
+	Stub          string `json:"stub,omitempty"`            // Page kept to serve the old import path
+	Alias         string `json:"alias,omitempty"`           // Old page URL redirecting to the new page
+	DryRun        bool   `json:"dry_run,omitempty"`
+}
+
+func renameCommand() *command {
+	return &command{
+		name:    "rename",
+		summary: "Rename a package",
+		usage:   "<package-name> <new-name> [options]",
+		description: "The package page and its localized pages are moved, and a default import path follows the new name.\n" +
+			"With --stub, a retired page keeps the old import path resolving; with --alias, the old page URL redirects to the new page.\n" +
+			"An alias serves no go-import tag, so it needs the import path to be kept; use --stub when it changes.\n" +
+			"Changing an import path other registry modules require needs --stub or --force.",
+		examples: []string{
+			"oldname newname --stub",
+			"oldname newname --import-path go.ngs.io/newname/v2",
+			"oldname newname --import-path go.ngs.io/oldname --alias    # Keep the import path, redirect the old page URL",
+			"oldname newname --dry-run",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var opts renameOptions
+			fs.StringVar(&opts.importPath, "import-path", "", "New import path (default: <domain>/<new-name> if the old one was the default, else unchanged)")
+			fs.StringVar(&opts.domain, "domain", "", "Domain of the package when the name exists in several domains")
+			fs.BoolVar(&opts.stub, "stub", false, "Keep a retired page serving the old import path")
+			fs.BoolVar(&opts.alias, "alias", false, "Redirect the old page URL to the new page")
+			fs.BoolVar(&opts.force, "force", false, "Change the import path even if other registry modules require it")
+			fs.BoolVar(&opts.dryRun, "dry-run", false, "Show what would change without making changes")
+			fs.BoolVar(&opts.allowBuildErrors, "allow-build-errors", false, "Only warn when the site fails to build after the change")
+
+			return func(a *app, args []string) error {
+				if len(args) != 2 {
+					return errUsage
+				}
+				if opts.stub && opts.alias {
+					return fmt.Errorf("--stub and --alias cannot be used together")
+				}
+				if !opts.dryRun {
+					release, err := a.lockRepository()
+					if err != nil {
+						return err
+					}
+					defer release()
+				}
+				return a.renamePackage(args[0], args[1], opts)
+			}
+		},
+	}
+}
+
+func (a *app) renamePackage(name, newName string, opts renameOptions) error {
+	if err := modpath.CheckName(newName); err != nil {
+		return err
+	}
+	f, err := a.findPackage(name, opts.domain)
+	if err != nil {
+		return err
+	}
+	d, err := a.cfg.Lookup(f.Domain)
+	if err != nil {
+		return err
+	}
+	pkg, err := hugo.ReadPackage(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+	if pkg.Retired {
+		return fmt.Errorf("%s is a retired stub and cannot be renamed", name)
+	}
+
+	newPath := filepath.Join(filepath.Dir(f.Path), newName+".md")
+	if newName == name {
+		return fmt.Errorf("package is already named %s", name)
+	}
+	if len(existing(newPath)) > 0 {
+		return fmt.Errorf("package page already exists: %s", newPath)
+	}
+
+	// A default import path follows the name; a custom one is kept
+	oldImportPath, importPath := pkg.ImportPath, opts.importPath
+	if importPath == "" {
+		importPath = oldImportPath
+		if oldImportPath == d.ImportPath(name) {
+			importPath = d.ImportPath(newName)
+		}
+	}
+	pathChanged := importPath != oldImportPath
+
+	if opts.stub && !pathChanged {
+		return fmt.Errorf("--stub needs a new import path; %s is kept", oldImportPath)
+	}
+	// The redirect page has no go-import tag, so go get of the old path breaks
+	if opts.alias && pathChanged {
+		return fmt.Errorf("--alias serves no go-import tag, but the import path changes from %s to %s; use --stub, or keep it with --import-path %s", oldImportPath, importPath, oldImportPath)
+	}
+	if pathChanged {
+		if err := modpath.CheckImportPath(importPath, d.Domain); err != nil {
+			return err
+		}
+		if err := modpath.CheckVersion(importPath, pkg.Version); err != nil {
+			return err
+		}
+		registry, err := hugo.ImportPaths(a.cfg.ContentDomains())
+		if err != nil {
+			return fmt.Errorf("failed to read registry import paths: %w", err)
+		}
+		// The old import path only stays registered with a stub
+		if !opts.stub {
+			registry = slices.DeleteFunc(registry, func(p string) bool { return p == oldImportPath })
+		}
+		if conflict := modpath.Conflict(importPath, registry); conflict == importPath {
+			return fmt.Errorf("import path %s is already registered", importPath)
+		} else if conflict != "" {
+			return fmt.Errorf("import path %s collides with registered %s", importPath, conflict)
+		}
+		if !opts.stub && !opts.force {
+			if err := a.checkDependents(oldImportPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	result := renameResult{
+		Name:       a.displayName(f),
+		NewName:    newName,
+		Domain:     f.Domain,
+		File:       newPath,
+		ImportPath: importPath,
+		DryRun:     opts.dryRun,
+	}
+	if pathChanged {
+		result.OldImportPath = oldImportPath
+	}
+	if opts.dryRun {
+		a.println("(DRY RUN - no changes will be made)")
+	}
+
+	renamed := *pkg
+	if renamed.Title == name {
+		renamed.Title = newName
+	}
+	if pathChanged {
+		renamed.ImportPath = importPath
+		if renamed.DocumentationURL == "https://pkg.go.dev/"+oldImportPath {
+			renamed.DocumentationURL = "https://pkg.go.dev/" + importPath
+		}
+		renamed.Commands = nil
+		for _, cmd := range pkg.Commands {
+			renamed.Commands = append(renamed.Commands, importPath+strings.TrimPrefix(cmd, oldImportPath))
+		}
+	}
+	if opts.alias {
+		// Relative, so each language redirects within its own section
+		renamed.Aliases = append(slices.Clone(pkg.Aliases), name)
+		result.Alias = name
+	}
+
+	// Mirrored README images move with the package
+	mirror := assets.NewMirror(d.StaticDir)
+	oldPrefix, newPrefix := assets.URLPrefix+name+"/", assets.URLPrefix+newName+"/"
+	renamed.Body = strings.ReplaceAll(pkg.Body, oldPrefix, newPrefix)
+	renamed.Localizations = map[string]*hugo.Localization{}
+	for lang, l := range pkg.Localizations {
+		renamed.Localizations[lang] = &hugo.Localization{
+			Description: l.Description,
+			Body:        strings.ReplaceAll(l.Body, oldPrefix, newPrefix),
+		}
+	}
+
+	a.printf("Renaming %s to %s\n", f.Path, newPath)
+	if pathChanged {
+		a.printf("Import path: %s -> %s\n", oldImportPath, importPath)
+	}
+	if opts.dryRun {
+		a.printf("✓ Would rename %s to %s\n", result.Name, newName)
+		if a.json() {
+			return a.writeJSON(result)
+		}
+		return nil
+	}
+
+	// Write the new page before removing the old one, so a failure never
+	// loses the package
+	if err := hugo.WritePackage(newPath, &renamed); err != nil {
+		return fmt.Errorf("failed to write package file: %w", err)
+	}
+	if _, err := hugo.RemovePackage(f.Path); err != nil {
+		return err
+	}
+	if opts.stub {
+		if err := hugo.WritePackage(f.Path, pkg.Stub("Renamed to "+importPath+".")); err != nil {
+			return fmt.Errorf("failed to write stub: %w", err)
+		}
+		result.Stub = f.Path
+		a.printf("Kept stub %s for %s\n", f.Path, oldImportPath)
+	}
+
+	if dir := mirror.Dir(name); len(existing(dir)) > 0 {
+		if err := os.Rename(dir, mirror.Dir(newName)); err != nil {
+			return fmt.Errorf("failed to move %s: %w", dir, err)
+		}
+	}
+	// Feed entries link to the page of the package; the history of the
+	// primary domain may hold entries of the others
+	inDomain := func(importPath string) bool { return a.cfg.DomainOf(importPath).Domain == d.Domain }
+	for _, releasesPath := range slices.Compact([]string{d.ReleasesPath(), a.cfg.ReleasesPath()}) {
+		if err := hugo.RenameReleases(releasesPath, name, newName, inDomain); err != nil {
+			return fmt.Errorf("failed to rename releases in %s: %w", releasesPath, err)
+		}
+	}
+	// API documentation is regenerated under the new name by vanity generate docs
+	docs := filepath.Join(d.ContentDir, hugo.DocsSection, name+".html")
+	if err := os.Remove(docs); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", docs, err)
+	}
+
+	a.printf("✓ Renamed %s to %s (%s)\n", result.Name, newName, importPath)
+
+	a.println()
+	if err := a.checkSite(f.Domain, opts.allowBuildErrors); err != nil {
+		return err
+	}
+
+	if a.json() {
+		return a.writeJSON(result)
+	}
+	return nil
+}
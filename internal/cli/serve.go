@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/site"
+)
+
+func serveCommand() *command {
+	return &command{
+		name:        "serve",
+		summary:     "Serve the site of a domain locally with hugo server",
+		usage:       "[options] [-- hugo-flags...]",
+		description: "Arguments after -- are passed to hugo server.",
+		examples: []string{
+			"                           # Serve the primary domain",
+			"--domain go.example.com    # Serve another domain",
+			"-- --port 1314",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var domain string
+			fs.StringVar(&domain, "domain", "", "Domain to serve (default: primary domain)")
+
+			return func(a *app, args []string) error {
+				d, err := a.cfg.Lookup(domain)
+				if err != nil {
+					return err
+				}
+				a.printf("Serving %s from %s\n", d.Domain, d.ContentDir)
+				if err := site.Serve(a.cfg, d.Domain, args...); err != nil {
+					return fmt.Errorf("hugo server failed: %w", err)
+				}
+				return nil
+			}
+		},
+	}
+}
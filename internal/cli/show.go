@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/hugo"
+	"gopkg.in/yaml.v3"
+)
+
+func showCommand() *command {
+	return &command{
+		name:    "show",
+		summary: "Show the metadata of a package",
+		usage:   "<package-name> [options]",
+		examples: []string{
+			"freecal",
+			"freecal --output json    # Full frontmatter",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var domain string
+			fs.StringVar(&domain, "domain", "", "Domain of the package when the name exists in several domains")
+
+			return func(a *app, args []string) error {
+				if len(args) != 1 {
+					return errUsage
+				}
+				return a.showPackage(args[0], domain)
+			}
+		},
+	}
+}
+
+func (a *app) showPackage(name, domain string) error {
+	f, err := a.findPackage(name, domain)
+	if err != nil {
+		return err
+	}
+	pkg, err := hugo.ReadPackage(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+
+	if a.json() {
+		return a.writeJSON(frontmatter(f, pkg))
+	}
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(a.out, "%-14s %s\n", label+":", value)
+		}
+	}
+	field("Name", a.displayName(f))
+	field("File", f.Path)
+	field("Title", pkg.Title)
+	field("Import Path", pkg.ImportPath)
+	field("Repository", pkg.RepoURL)
+	field("Version", pkg.Version)
+	field("Description", pkg.Description)
+	field("Deprecated", pkg.Deprecated)
+	field("License", pkg.License)
+	field("Author", pkg.Author)
+	field("Go", pkg.GoVersion)
+	field("Toolchain", pkg.Toolchain)
+	field("Documentation", pkg.DocumentationURL)
+	field("Commands", strings.Join(pkg.Commands, ", "))
+	if len(pkg.Requires) > 0 {
+		var requires []string
+		for _, r := range pkg.Requires {
+			requires = append(requires, r.Path+"@"+r.Version)
+		}
+		field("Requires", strings.Join(requires, ", "))
+	}
+	if len(pkg.Versions) > 0 {
+		field("Versions", fmt.Sprint(len(pkg.Versions)))
+	}
+	if len(pkg.Examples) > 0 {
+		field("Examples", fmt.Sprint(len(pkg.Examples)))
+	}
+	var languages []string
+	for lang := range pkg.Localizations {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	field("Languages", strings.Join(languages, ", "))
+	if !pkg.UpdatedAt.IsZero() {
+		field("Updated", pkg.UpdatedAt.Format("2006-01-02"))
+	}
+	if a.verbose && pkg.Body != "" {
+		fmt.Fprintf(a.out, "\n%s", pkg.Body)
+	}
+	return nil
+}
+
+// frontmatter returns the frontmatter of a package keyed as in the page,
+// with the domain and file it was read from.
+func frontmatter(f hugo.PackageFile, pkg *hugo.Package) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if data, err := yaml.Marshal(pkg); err == nil {
+		yaml.Unmarshal(data, &fields)
+	}
+	fields["domain"] = f.Domain
+	fields["file"] = f.Path
+	return fields
+}
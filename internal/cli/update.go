@@ -0,0 +1,524 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/assets"
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/github"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/refresh"
+	"go.ngs.io/internal/source"
+	"golang.org/x/mod/semver"
+)
+
+type updateOptions struct {
+	dryRun        bool
+	updateAuthor  bool
+	updateMissing bool
+	sourceName    string
+	cacheDir      string
+	mirrorAssets  bool
+	domain        string
+
+	allowBuildErrors bool
+}
+
+// updateResult is the outcome of updating a package, also its JSON result.
+type updateResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // updated, skipped, error or missing
+	Message string `json:"message"`
+	err     error
+}
+
+func updateCommand() *command {
+	return &command{
+		name:        "update",
+		summary:     "Update Go packages metadata from GitHub API",
+		usage:       "[package-names...] [options]",
+		description: "If no package names are provided, all packages will be updated.",
+		examples: []string{
+			"                    # Update all packages",
+			"freecal servedir    # Update specific packages",
+			"--dry-run           # Preview changes without updating",
+			"--update-missing    # Update timestamps for missing repos",
+			"--source git        # Read metadata from local git mirrors",
+			"--domain go.example.com",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var opts updateOptions
+			fs.BoolVar(&opts.dryRun, "dry-run", false, "Show what would be updated without making changes")
+			fs.BoolVar(&opts.updateAuthor, "update-author", false, "Also update author information from GitHub")
+			fs.BoolVar(&opts.updateMissing, "update-missing", false, "Update timestamps to current date for repositories that return 404")
+			fs.StringVar(&opts.sourceName, "source", source.NameGitHub, "Metadata source: github or git (local bare mirrors)")
+			fs.StringVar(&opts.cacheDir, "cache-dir", "", "Directory for git mirrors (default: user cache directory)")
+			fs.BoolVar(&opts.mirrorAssets, "mirror-assets", false, "Download README images into static/ from now on (recorded as mirror_assets in each page)")
+			fs.StringVar(&opts.domain, "domain", "", "Update only the packages of this domain (default: all domains)")
+			fs.BoolVar(&opts.allowBuildErrors, "allow-build-errors", false, "Only warn when the site fails to build after the update")
+
+			return func(a *app, args []string) error {
+				src, err := source.New(opts.sourceName, opts.cacheDir)
+				if err != nil {
+					return err
+				}
+				domains, err := a.cfg.Select(opts.domain)
+				if err != nil {
+					return err
+				}
+				if !opts.dryRun {
+					release, err := a.lockRepository()
+					if err != nil {
+						return err
+					}
+					defer release()
+				}
+				return a.updatePackages(opts, src, domains, args)
+			}
+		},
+	}
+}
+
+func (a *app) updatePackages(opts updateOptions, src source.Source, domains []*config.Config, specificPackages []string) error {
+	dryRun, updateAuthor, updateMissing := opts.dryRun, opts.updateAuthor, opts.updateMissing
+
+	a.printf("Updating packages from %s...\n", src.Name())
+	if dryRun {
+		a.println("(DRY RUN - no changes will be made)")
+	}
+	a.println()
+
+	// Get list of package files of all domains
+	allFiles, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	// Import paths of all packages to find requirements between them
+	registry := make([]string, 0, len(allFiles))
+	for _, f := range allFiles {
+		if f.Err == nil {
+			registry = append(registry, f.ImportPath)
+		}
+	}
+
+	// Keep the selected domains and the requested packages
+	mirrors := map[string]*assets.Mirror{}
+	releaseFiles := map[string]string{}
+	for _, d := range domains {
+		mirrors[d.Domain] = assets.NewMirror(d.StaticDir)
+		mirrors[d.Domain].DryRun = dryRun
+		releaseFiles[d.Domain] = d.ReleasesPath()
+	}
+	packageFiles := []hugo.PackageFile{}
+	for _, f := range allFiles {
+		if _, ok := mirrors[f.Domain]; !ok {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(f.Path), ".md")
+		if len(specificPackages) > 0 && !slices.Contains(specificPackages, name) {
+			continue
+		}
+		packageFiles = append(packageFiles, f)
+	}
+	if len(specificPackages) > 0 && len(packageFiles) == 0 {
+		return fmt.Errorf("no matching packages found")
+	}
+
+	// Process each package
+	results := []updateResult{}
+	updatedCount := 0
+	skippedCount := 0
+	errorCount := 0
+
+	for _, f := range packageFiles {
+		name := strings.TrimSuffix(filepath.Base(f.Path), ".md")
+		result := processPackage(src, mirrors[f.Domain], opts.mirrorAssets, releaseFiles[f.Domain], registry, f.Path, name, dryRun, updateAuthor, updateMissing)
+		result.Name = a.displayName(f)
+		results = append(results, result)
+
+		// Print result immediately
+		switch result.Status {
+		case "updated":
+			a.printf("✓ %s - %s\n", result.Name, result.Message)
+			updatedCount++
+		case "skipped":
+			a.printf("○ %s - %s\n", result.Name, result.Message)
+			skippedCount++
+		case "error":
+			a.printf("✗ %s - %s\n", result.Name, result.Message)
+			errorCount++
+		case "missing":
+			if updateMissing {
+				a.printf("⚠ %s - %s\n", result.Name, result.Message)
+				updatedCount++
+			} else {
+				a.printf("○ %s - %s\n", result.Name, result.Message)
+				skippedCount++
+			}
+		}
+	}
+
+	// Remove mirrored images that are no longer referenced, including those
+	// of packages that stopped mirroring
+	for _, d := range domains {
+		mirror := mirrors[d.Domain]
+		if dryRun {
+			continue
+		}
+
+		// Images of unreadable pages are kept, as they may still be in use
+		bodies := map[string]string{}
+		removeOrphans := len(specificPackages) == 0
+		for _, f := range packageFiles {
+			if f.Domain != d.Domain {
+				continue
+			}
+			pkg, err := hugo.ReadPackage(f.Path)
+			if err != nil {
+				removeOrphans = false
+				continue
+			}
+			body := pkg.Body
+			for _, localization := range pkg.Localizations {
+				body += "\n" + localization.Body
+			}
+			bodies[strings.TrimSuffix(filepath.Base(f.Path), ".md")] = body
+		}
+
+		removed, err := mirror.Prune(bodies, removeOrphans)
+		if err != nil {
+			a.printf("Warning: Could not prune mirrored images: %v\n", err)
+		}
+		for _, path := range removed {
+			a.printf("Removed unused image %s\n", path)
+		}
+	}
+
+	// Validate site build if not dry run and changes were made
+	var buildErr error
+	if !dryRun && updatedCount > 0 {
+		for _, d := range domains {
+			a.println()
+			if err := a.checkSite(d.Domain, opts.allowBuildErrors); err != nil && buildErr == nil {
+				buildErr = err
+			}
+		}
+	}
+
+	// Print summary
+	a.printf("\nSummary: %d updated", updatedCount)
+	if skippedCount > 0 {
+		a.printf(", %d skipped", skippedCount)
+	}
+	if errorCount > 0 {
+		a.printf(", %d errors", errorCount)
+	}
+	a.println()
+
+	if a.json() {
+		if err := a.writeJSON(results); err != nil {
+			return err
+		}
+	}
+
+	// Return error if any packages failed
+	if errorCount > 0 {
+		return fmt.Errorf("%d packages failed to update", errorCount)
+	}
+
+	return buildErr
+}
+
+func processPackage(src source.Source, mirror *assets.Mirror, mirrorAssets bool, releasesPath string, registry []string, filePath, name string, dryRun, updateAuthor, updateMissing bool) updateResult {
+	// Read existing package
+	pkg, err := hugo.ReadPackage(filePath)
+	if err != nil {
+		return updateResult{
+			Name:    name,
+			Status:  "error",
+			Message: fmt.Sprintf("failed to read package: %v", err),
+			err:     err,
+		}
+	}
+
+	// Retired stubs only serve the import path
+	if pkg.Retired {
+		return updateResult{
+			Name:    name,
+			Status:  "skipped",
+			Message: "retired",
+		}
+	}
+
+	// Skip if no repo URL
+	if pkg.RepoURL == "" {
+		return updateResult{
+			Name:    name,
+			Status:  "skipped",
+			Message: "no repository URL",
+		}
+	}
+
+	// Parse repository URL
+	if src.Name() == source.NameGitHub {
+		if _, _, err := github.ParseRepoURL(pkg.RepoURL); err != nil {
+			return updateResult{
+				Name:    name,
+				Status:  "error",
+				Message: fmt.Sprintf("invalid repository URL: %v", err),
+				err:     err,
+			}
+		}
+	}
+
+	// Fetch repository metadata
+	repoInfo, err := src.Repository(pkg.RepoURL)
+	if err != nil {
+		// Always return error for non-existent repositories
+		return updateResult{
+			Name:    name,
+			Status:  "error",
+			Message: fmt.Sprintf("failed to fetch repository %s: %v", pkg.RepoURL, err),
+			err:     err,
+		}
+	}
+
+	// Check what needs updating
+	changes := []string{}
+
+	// Always update timestamps from the source
+	if pkg.CreatedAt != repoInfo.CreatedAt {
+		pkg.CreatedAt = repoInfo.CreatedAt
+		changes = append(changes, "created_at")
+	}
+	if pkg.UpdatedAt != repoInfo.UpdatedAt {
+		pkg.UpdatedAt = repoInfo.UpdatedAt
+		changes = append(changes, "updated_at")
+	}
+
+	// Update default branch
+	if repoInfo.DefaultBranch != "" && pkg.DefaultBranch != repoInfo.DefaultBranch {
+		pkg.DefaultBranch = repoInfo.DefaultBranch
+		changes = append(changes, "default_branch")
+	}
+
+	// Update description; git mirrors carry no description to compare with
+	if src.Name() == source.NameGitHub && pkg.Description != repoInfo.Description {
+		pkg.Description = repoInfo.Description
+		if repoInfo.Description == "" {
+			changes = append(changes, "description cleared")
+		} else {
+			changes = append(changes, "description")
+		}
+	}
+
+	// Update license, classifying LICENSE files when the source has none
+	license, licenseNote := repoInfo.License, ""
+	if license == "" {
+		license, licenseNote, _ = refresh.License(src, pkg.RepoURL, "")
+	}
+	if license != "" {
+		if pkg.License != license || pkg.LicenseNote != licenseNote {
+			pkg.License = license
+			pkg.LicenseNote = licenseNote
+			changes = append(changes, "license")
+		}
+	}
+
+	// Update author if requested
+	if updateAuthor {
+		newAuthor := repoInfo.OwnerName
+		if newAuthor == "" {
+			newAuthor = repoInfo.OwnerLogin
+		}
+		if newAuthor != "" && pkg.Author != newAuthor {
+			pkg.Author = newAuthor
+			changes = append(changes, "author")
+		}
+	}
+
+	// Fetch version history and go.mod retractions
+	versions, err := refresh.Versions(src, pkg.RepoURL)
+	if err == nil {
+		modFile, modErr := refresh.ModFile(src, pkg.RepoURL, pkg.ImportPath, versions)
+		if modErr == nil {
+			refresh.MarkRetracted(versions, modFile)
+
+			// Update go and toolchain directives and dependency count
+			if pkg.GoVersion != modFile.Go || pkg.Toolchain != modFile.Toolchain {
+				pkg.GoVersion = modFile.Go
+				pkg.Toolchain = modFile.Toolchain
+				changes = append(changes, "go version")
+			}
+			if dependencies := modFile.DirectDependencies(); pkg.Dependencies != dependencies {
+				pkg.Dependencies = dependencies
+				changes = append(changes, "dependencies")
+			}
+
+			// Update requirements on other registry modules
+			if requires := refresh.Requirements(modFile, pkg.ImportPath, registry); !slices.Equal(requires, pkg.Requires) {
+				pkg.Requires = requires
+				changes = append(changes, "requires")
+			}
+
+			// Update deprecation notice
+			if pkg.Deprecated != modFile.Deprecated {
+				pkg.Deprecated = modFile.Deprecated
+				if modFile.Deprecated == "" {
+					changes = append(changes, "deprecation cleared")
+				} else {
+					changes = append(changes, "deprecated")
+				}
+			}
+		}
+
+		if !refresh.VersionsEqual(pkg.Versions, versions) {
+			pkg.Versions = versions
+			changes = append(changes, "versions")
+		}
+	}
+
+	// Update version, skipping retracted ones
+	version := refresh.LatestVersion(pkg.Versions, pkg.ImportPath)
+	if version == "" && len(pkg.Versions) == 0 {
+		version, _ = src.LatestVersion(pkg.RepoURL)
+	}
+	var release *hugo.Release
+	if version != "" && pkg.Version != version {
+		oldVersion := pkg.Version
+		pkg.Version = version
+		if oldVersion == "" {
+			changes = append(changes, fmt.Sprintf("version: %s", version))
+		} else {
+			changes = append(changes, fmt.Sprintf("version: %s → %s", oldVersion, version))
+		}
+		// A retraction can move the version backwards, which is no release
+		if oldVersion == "" || semver.Compare(version, oldVersion) > 0 {
+			release = &hugo.Release{
+				Event:           hugo.EventRelease,
+				Name:            name,
+				ImportPath:      pkg.ImportPath,
+				Version:         version,
+				PreviousVersion: oldVersion,
+				URL:             src.ReleaseURL(pkg.RepoURL, version),
+				Date:            time.Now().UTC().Truncate(time.Second),
+			}
+			// Date the release by its tag when the history has it
+			for _, v := range pkg.Versions {
+				if v.Version == version && !v.Date.IsZero() {
+					release.Date = v.Date.UTC()
+					break
+				}
+			}
+		}
+	}
+
+	// Detect installable commands
+	commands, err := refresh.Commands(src, pkg.RepoURL, pkg.Version, pkg.ImportPath)
+	if err == nil && strings.Join(commands, " ") != strings.Join(pkg.Commands, " ") {
+		pkg.Commands = commands
+		changes = append(changes, "commands")
+	}
+
+	// Collect Example functions
+	examples, err := refresh.Examples(src, pkg.RepoURL, pkg.Version, pkg.ImportPath)
+	if err == nil && !slices.Equal(examples, pkg.Examples) {
+		pkg.Examples = examples
+		changes = append(changes, "examples")
+	}
+
+	// Read usage text from the configured file
+	if pkg.UsageFile != "" {
+		usage, err := refresh.Usage(src, pkg.RepoURL, pkg.Version, pkg.UsageFile)
+		if err == nil && usage != pkg.Usage {
+			pkg.Usage = usage
+			changes = append(changes, "usage")
+		}
+	}
+
+	// Packages keep mirroring their images once it was asked for
+	if mirrorAssets && !pkg.MirrorAssets {
+		pkg.MirrorAssets = true
+		changes = append(changes, "mirror_assets")
+	}
+
+	// Fetch and update README with links resolved against the repository.
+	// On dry run the mirror only names the images, so the comparison holds.
+	readme, sanitized, err := refresh.Readme(src, pkg.RepoURL, pkg.DefaultBranch, pkg.Title, pkg.ImportPath)
+	if err == nil && pkg.MirrorAssets && readme != "" {
+		// Failed images keep their remote URL and are retried next run
+		readme, _ = mirror.Rewrite(name, readme)
+	}
+	if err == nil && readme != pkg.Body {
+		pkg.Body = readme
+		if readme == "" {
+			changes = append(changes, "readme cleared")
+		} else if len(sanitized) > 0 {
+			changes = append(changes, fmt.Sprintf("readme (sanitized: %s)", strings.Join(sanitized, "; ")))
+		} else {
+			changes = append(changes, "readme")
+		}
+	}
+
+	// Fetch localized READMEs such as README.ja.md
+	localized, localizedSanitized, err := refresh.LocalizedReadmes(src, pkg.RepoURL, pkg.DefaultBranch, hugo.Languages, pkg.Title, pkg.ImportPath)
+	if err == nil {
+		if pkg.MirrorAssets {
+			for lang, body := range localized {
+				localized[lang], _ = mirror.Rewrite(name, body)
+			}
+		}
+		languages := refresh.Localize(pkg, localized)
+		for _, lang := range languages {
+			changes = append(changes, "readme."+lang)
+		}
+		if len(languages) > 0 && len(localizedSanitized) > 0 {
+			changes = append(changes, fmt.Sprintf("sanitized: %s", strings.Join(localizedSanitized, "; ")))
+		}
+	}
+
+	// Check if any changes were made
+	if len(changes) == 0 {
+		return updateResult{
+			Name:    name,
+			Status:  "skipped",
+			Message: "already up to date",
+		}
+	}
+
+	// Write changes if not dry run
+	if !dryRun {
+		if err := hugo.WritePackage(filePath, pkg); err != nil {
+			return updateResult{
+				Name:    name,
+				Status:  "error",
+				Message: fmt.Sprintf("failed to write package: %v", err),
+				err:     err,
+			}
+		}
+
+		// Record version transition for the release feeds
+		if release != nil {
+			if err := hugo.AppendRelease(releasesPath, *release); err != nil {
+				return updateResult{
+					Name:    name,
+					Status:  "error",
+					Message: fmt.Sprintf("failed to record release: %v", err),
+					err:     err,
+				}
+			}
+		}
+	}
+
+	return updateResult{
+		Name:    name,
+		Status:  "updated",
+		Message: strings.Join(changes, ", "),
+	}
+}
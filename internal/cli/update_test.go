@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.ngs.io/internal/assets"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/source"
+)
+
+// versionSource serves tags and a go.mod, and no other files.
+type versionSource struct {
+	versions []source.Version
+	gomod    string
+}
+
+func (s versionSource) Name() string { return "fake" }
+
+func (s versionSource) Repository(repoURL string) (*source.Repository, error) {
+	return &source.Repository{DefaultBranch: "main"}, nil
+}
+
+func (s versionSource) Versions(repoURL string) ([]source.Version, error) { return s.versions, nil }
+
+func (s versionSource) LatestVersion(repoURL string) (string, error) { return "", nil }
+
+func (s versionSource) Readme(repoURL string) (string, error) { return "", nil }
+
+func (s versionSource) ReleaseURL(repoURL, tag string) string {
+	return repoURL + "/releases/tag/" + tag
+}
+
+func (s versionSource) File(repoURL, filePath, ref string) ([]byte, error) {
+	if filePath == "go.mod" {
+		return []byte(s.gomod), nil
+	}
+	return nil, fmt.Errorf("%s: %w", filePath, fs.ErrNotExist)
+}
+
+func (s versionSource) ListDir(repoURL, dirPath, ref string) ([]source.DirEntry, error) {
+	return []source.DirEntry{{Name: "go.mod", Path: "go.mod"}}, nil
+}
+
+func TestProcessPackageReleases(t *testing.T) {
+	tags := []source.Version{
+		{Name: "v1.1.0", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "v1.0.0", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	gomod := "module go.ngs.io/widget\n\ngo 1.22\n"
+
+	tests := []struct {
+		name        string
+		version     string // on the page before the update
+		gomod       string
+		wantVersion string
+		want        []hugo.Release
+	}{
+		{
+			name:        "first version",
+			gomod:       gomod,
+			wantVersion: "v1.1.0",
+			want:        []hugo.Release{{Event: hugo.EventRelease, Version: "v1.1.0", Date: tags[0].Date}},
+		},
+		{
+			name:        "newer version",
+			version:     "v1.0.0",
+			gomod:       gomod,
+			wantVersion: "v1.1.0",
+			want:        []hugo.Release{{Event: hugo.EventRelease, Version: "v1.1.0", PreviousVersion: "v1.0.0", Date: tags[0].Date}},
+		},
+		{
+			name:        "up to date",
+			version:     "v1.1.0",
+			gomod:       gomod,
+			wantVersion: "v1.1.0",
+		},
+		{
+			// The version moves back to v1.0.0, which is no release
+			name:        "retracted version",
+			version:     "v1.1.0",
+			gomod:       gomod + "\nretract v1.1.0\n",
+			wantVersion: "v1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			filePath := filepath.Join(dir, "widget.md")
+			pkg := &hugo.Package{Title: "widget", ImportPath: "go.ngs.io/widget", RepoURL: "https://github.com/ngs/widget", Version: tt.version}
+			if err := hugo.WritePackage(filePath, pkg); err != nil {
+				t.Fatal(err)
+			}
+			releasesPath := filepath.Join(dir, hugo.ReleasesFile)
+
+			src := versionSource{versions: tags, gomod: tt.gomod}
+			result := processPackage(src, assets.NewMirror(dir), false, releasesPath, nil, filePath, "widget", false, false, false)
+			if result.err != nil {
+				t.Fatal(result.err)
+			}
+
+			releases, err := hugo.ReadReleases(releasesPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].Name = "widget"
+				tt.want[i].ImportPath = "go.ngs.io/widget"
+				tt.want[i].URL = "https://github.com/ngs/widget/releases/tag/" + tt.want[i].Version
+			}
+			if !reflect.DeepEqual(releases, tt.want) {
+				t.Errorf("releases = %+v, want %+v", releases, tt.want)
+			}
+
+			updated, err := hugo.ReadPackage(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.Version != tt.wantVersion {
+				t.Errorf("version = %s, want %s", updated.Version, tt.wantVersion)
+			}
+		})
+	}
+}
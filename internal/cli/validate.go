@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"go.ngs.io/internal/site"
+)
+
+// validateResult is a domain in the JSON result of validate.
+type validateResult struct {
+	Domain   string `json:"domain"`
+	Packages int    `json:"packages"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+
+	Errors []site.BuildError `json:"errors,omitempty"` // Build errors by file and line
+}
+
+func validateCommand() *command {
+	return &command{
+		name:    "validate",
+		summary: "Check that package pages can be read and the site builds",
+		usage:   "[options]",
+		description: "Every package page is read, import paths must be unique across domains, and the site of each domain is built with Hugo\n" +
+			"into a temporary directory, leaving the output directory alone. Build errors are reported by content file and line.",
+		examples: []string{
+			"                           # All domains",
+			"--domain go.example.com    # One domain",
+		},
+		setup: func(fs *pflag.FlagSet) runFunc {
+			var domain string
+			fs.StringVar(&domain, "domain", "", "Validate only this domain (default: all domains)")
+
+			return func(a *app, args []string) error {
+				if len(args) > 0 {
+					return errUsage
+				}
+				return a.validate(domain)
+			}
+		},
+	}
+}
+
+func (a *app) validate(domain string) error {
+	domains, err := a.cfg.Select(domain)
+	if err != nil {
+		return err
+	}
+
+	// Listing reads every page and rejects duplicate import paths
+	packageFiles, err := a.packages()
+	if err != nil {
+		return err
+	}
+
+	results := []validateResult{}
+	failed := 0
+	for _, d := range domains {
+		result := validateResult{Domain: d.Domain, OK: true}
+		for _, f := range packageFiles {
+			if f.Domain != d.Domain {
+				continue
+			}
+			result.Packages++
+			if f.Err != nil {
+				buildErr := site.PageError(f.Err)
+				if buildErr.File == "" {
+					buildErr.File = f.Path
+				}
+				result.Errors = append(result.Errors, buildErr)
+			}
+		}
+
+		// The build would report the same pages again
+		if len(result.Errors) > 0 {
+			result.OK = false
+			result.Error = "package pages cannot be read"
+		} else if err := a.validateSite(d.Domain); err != nil {
+			result.OK = false
+			result.Error = "site build failed"
+			result.Errors = buildErrors(err)
+		}
+
+		if result.OK {
+			a.printf("✓ %s - %d packages, site builds successfully\n", d.Domain, result.Packages)
+		} else {
+			a.printf("✗ %s - %s\n", d.Domain, result.Error)
+			for _, buildErr := range result.Errors {
+				a.printf("  %v\n", buildErr)
+			}
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if a.json() {
+		if err := a.writeJSON(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d domains failed validation", failed)
+	}
+	return nil
+}
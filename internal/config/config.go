@@ -0,0 +1,234 @@
+// Package config loads the project configuration shared by all commands:
+// the vanity domains, where repositories live and the text of the site.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.ngs.io/internal/hugo"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the configuration file read from the repository root.
+const DefaultFile = "vanity.yaml"
+
+// Config is the configuration of a vanity domain. The configuration file
+// describes the primary domain; Domains holds the other domains served from
+// the repository, which inherit owner, forge and site settings from it.
+type Config struct {
+	Domain     string    `yaml:"domain"`      // Vanity domain, e.g. go.ngs.io
+	Owner      string    `yaml:"owner"`       // Default repository owner
+	Forge      string    `yaml:"forge"`       // Base URL of the default forge
+	ContentDir string    `yaml:"content_dir"` // Package pages
+	StaticDir  string    `yaml:"static_dir"`  // Mirrored assets
+	OutputDir  string    `yaml:"output_dir"`  // Built site, llms.txt and feeds
+	DataDir    string    `yaml:"data_dir"`    // Release history
+	Site       Site      `yaml:"site"`
+	Domains    []*Config `yaml:"-"`
+}
+
+// file is the layout of the configuration file. Domains are decoded one by
+// one on top of the settings they inherit.
+type file struct {
+	*Config `yaml:",inline"`
+	Domains []yaml.Node `yaml:"domains"`
+}
+
+// Site is the text describing the site in generated files.
+type Site struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Author      string `yaml:"author"`
+	AuthorURL   string `yaml:"author_url"`
+}
+
+// Default returns the configuration of go.ngs.io, used for settings missing
+// from the configuration file.
+func Default() *Config {
+	return &Config{
+		Domain:     "go.ngs.io",
+		Owner:      "ngs",
+		Forge:      "https://github.com",
+		ContentDir: "content",
+		StaticDir:  "static",
+		OutputDir:  "public",
+		DataDir:    "data",
+		Site: Site{
+			Title:       "go.ngs.io",
+			Description: "Go Module Vanity Import Path Service",
+			Author:      "Atsushi Nagase",
+			AuthorURL:   "https://ngs.io",
+		},
+	}
+}
+
+// Load reads the configuration file at filePath on top of the defaults. A
+// missing file yields the defaults.
+func Load(filePath string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	f := file{Config: cfg}
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	for _, node := range f.Domains {
+		domain := cfg.inherit()
+		if err := node.Decode(domain); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		domain.setDefaults()
+		cfg.Domains = append(cfg.Domains, domain)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filePath, err)
+	}
+
+	return cfg, nil
+}
+
+// inherit returns the settings a secondary domain starts from. The site
+// title defaults to the domain.
+func (c *Config) inherit() *Config {
+	site := c.Site
+	site.Title = ""
+	return &Config{
+		Owner: c.Owner,
+		Forge: c.Forge,
+		Site:  site,
+	}
+}
+
+// setDefaults fills the settings of a secondary domain missing from the
+// configuration file. Its directories default to domains/<domain>/.
+func (c *Config) setDefaults() {
+	base := filepath.Join("domains", c.Domain)
+	if c.ContentDir == "" {
+		c.ContentDir = filepath.Join(base, "content")
+	}
+	if c.StaticDir == "" {
+		c.StaticDir = filepath.Join(base, "static")
+	}
+	if c.OutputDir == "" {
+		c.OutputDir = filepath.Join(base, "public")
+	}
+	if c.DataDir == "" {
+		c.DataDir = filepath.Join(base, "data")
+	}
+	if c.Site.Title == "" {
+		c.Site.Title = c.Domain
+	}
+}
+
+func (c *Config) validate() error {
+	seen := map[string]string{}
+	for _, d := range c.All() {
+		if d.Domain == "" || strings.ContainsAny(d.Domain, ":/ ") {
+			return fmt.Errorf("domain must be a host name such as go.example.com, got %q", d.Domain)
+		}
+		if !strings.HasPrefix(d.Forge, "https://") && !strings.HasPrefix(d.Forge, "http://") {
+			return fmt.Errorf("%s: forge must be an http(s) URL, got %q", d.Domain, d.Forge)
+		}
+		if d.ContentDir == "" {
+			return fmt.Errorf("%s: content_dir must not be empty", d.Domain)
+		}
+		if d.DataDir == "" {
+			return fmt.Errorf("%s: data_dir must not be empty", d.Domain)
+		}
+
+		// Domains must not share directories, or their packages would mix
+		for _, key := range []string{d.Domain, filepath.Clean(d.ContentDir), filepath.Clean(d.StaticDir), filepath.Clean(d.OutputDir), filepath.Clean(d.DataDir)} {
+			if other, ok := seen[key]; ok {
+				return fmt.Errorf("%s and %s both use %s", other, d.Domain, key)
+			}
+			seen[key] = d.Domain
+		}
+	}
+	return nil
+}
+
+// All returns the primary domain followed by the other domains.
+func (c *Config) All() []*Config {
+	return append([]*Config{c}, c.Domains...)
+}
+
+// Lookup returns the configuration of a domain. An empty name is the
+// primary domain.
+func (c *Config) Lookup(domain string) (*Config, error) {
+	for _, d := range c.All() {
+		if domain == "" || d.Domain == domain {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown domain: %s", domain)
+}
+
+// Select returns the named domain, or all domains if domain is empty.
+func (c *Config) Select(domain string) ([]*Config, error) {
+	if domain == "" {
+		return c.All(), nil
+	}
+	d, err := c.Lookup(domain)
+	if err != nil {
+		return nil, err
+	}
+	return []*Config{d}, nil
+}
+
+// DomainOf returns the domain serving importPath: the domain it is under,
+// or the primary domain for custom import paths.
+func (c *Config) DomainOf(importPath string) *Config {
+	for _, d := range c.Domains {
+		if importPath == d.Domain || strings.HasPrefix(importPath, d.Domain+"/") {
+			return d
+		}
+	}
+	return c
+}
+
+// ContentDomains returns the content directory of every domain.
+func (c *Config) ContentDomains() []hugo.Domain {
+	var domains []hugo.Domain
+	for _, d := range c.All() {
+		domains = append(domains, hugo.Domain{Name: d.Domain, ContentDir: d.ContentDir})
+	}
+	return domains
+}
+
+// BaseURL returns the URL of the site root with a trailing slash.
+func (c *Config) BaseURL() string {
+	return "https://" + c.Domain + "/"
+}
+
+// ImportPath returns the default import path of a package.
+func (c *Config) ImportPath(name string) string {
+	return c.Domain + "/" + name
+}
+
+// RepoURL returns the default repository URL of a package.
+func (c *Config) RepoURL(name string) string {
+	return strings.TrimSuffix(c.Forge, "/") + "/" + c.Owner + "/" + name
+}
+
+// ReleasesPath returns the file recording the release history of the
+// domain.
+func (c *Config) ReleasesPath() string {
+	return filepath.Join(c.DataDir, hugo.ReleasesFile)
+}
+
+// PackagePath returns the content file of a package.
+func (c *Config) PackagePath(name string) string {
+	return filepath.Join(c.ContentDir, name+".md")
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadDomains(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+domain: go.ngs.io
+owner: ngs
+site:
+  title: Go modules of ngs
+  author: Atsushi Nagase
+domains:
+  - domain: go.example.com
+  - domain: go.example.org
+    owner: example-org
+    forge: https://gitlab.com
+    content_dir: sites/org/content
+    data_dir: sites/org/data
+    site:
+      title: Example modules
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := Default()
+	want := []*Config{
+		{
+			// Settings of the primary domain inherited, directories under domains/
+			Domain:     "go.example.com",
+			Owner:      "ngs",
+			Forge:      defaults.Forge,
+			ContentDir: filepath.Join("domains", "go.example.com", "content"),
+			StaticDir:  filepath.Join("domains", "go.example.com", "static"),
+			OutputDir:  filepath.Join("domains", "go.example.com", "public"),
+			DataDir:    filepath.Join("domains", "go.example.com", "data"),
+			Site: Site{
+				Title:       "go.example.com",
+				Description: defaults.Site.Description,
+				Author:      "Atsushi Nagase",
+				AuthorURL:   defaults.Site.AuthorURL,
+			},
+		},
+		{
+			Domain:     "go.example.org",
+			Owner:      "example-org",
+			Forge:      "https://gitlab.com",
+			ContentDir: "sites/org/content",
+			StaticDir:  filepath.Join("domains", "go.example.org", "static"),
+			OutputDir:  filepath.Join("domains", "go.example.org", "public"),
+			DataDir:    "sites/org/data",
+			Site: Site{
+				Title:       "Example modules",
+				Description: defaults.Site.Description,
+				Author:      "Atsushi Nagase",
+				AuthorURL:   defaults.Site.AuthorURL,
+			},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Domains, want) {
+		t.Errorf("Domains =\n%+v\n%+v\nwant\n%+v\n%+v", cfg.Domains[0], cfg.Domains[1], want[0], want[1])
+	}
+	if cfg.Site.Title != "Go modules of ngs" || cfg.ContentDir != "content" {
+		t.Errorf("primary domain = %+v", cfg)
+	}
+
+	for importPath, domain := range map[string]string{
+		"go.example.com/widget":    "go.example.com",
+		"go.example.org/tool/v2":   "go.example.org",
+		"go.example.comet/widget":  "go.ngs.io",
+		"github.com/ngs/something": "go.ngs.io",
+	} {
+		if got := cfg.DomainOf(importPath).Domain; got != domain {
+			t.Errorf("DomainOf(%q) = %s, want %s", importPath, got, domain)
+		}
+	}
+	if got, want := cfg.Domains[0].ReleasesPath(), filepath.Join("domains", "go.example.com", "data", "releases.yaml"); got != want {
+		t.Errorf("ReleasesPath = %s, want %s", got, want)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "shared content directory",
+			config: "domains:\n  - domain: go.example.com\n    content_dir: content/\n",
+			want:   "go.ngs.io and go.example.com both use content",
+		},
+		{
+			name:   "shared data directory",
+			config: "domains:\n  - domain: go.example.com\n    data_dir: ./data\n",
+			want:   "go.ngs.io and go.example.com both use data",
+		},
+		{
+			name:   "duplicate domain",
+			config: "domains:\n  - domain: go.ngs.io\n    content_dir: other\n",
+			want:   "go.ngs.io and go.ngs.io both use go.ngs.io",
+		},
+		{
+			name:   "domain with a path",
+			config: "domains:\n  - domain: example.com/go\n",
+			want:   `domain must be a host name such as go.example.com, got "example.com/go"`,
+		},
+		{
+			name:   "forge without scheme",
+			config: "forge: github.com\n",
+			want:   `go.ngs.io: forge must be an http(s) URL, got "github.com"`,
+		},
+		{
+			name:   "empty data directory",
+			config: "data_dir: \"\"\n",
+			want:   "go.ngs.io: data_dir must not be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.config))
+			if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
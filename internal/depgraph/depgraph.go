@@ -0,0 +1,84 @@
+// Package depgraph builds the graph of dependencies between the modules
+// hosted in the registry from the requirements stored on each package.
+package depgraph
+
+import (
+	"sort"
+	"strings"
+
+	"go.ngs.io/internal/hugo"
+	"golang.org/x/mod/semver"
+)
+
+// Edge is a requirement of one registry module on another.
+type Edge struct {
+	Dependent  *hugo.Package
+	Dependency *hugo.Package
+	Required   string // Version required by the dependent's go.mod
+}
+
+// Lagging reports whether the dependent requires an older version than the
+// latest version of the dependency.
+func (e Edge) Lagging() bool {
+	return e.Dependency.Version != "" && semver.Compare(e.Required, e.Dependency.Version) < 0
+}
+
+type Graph struct {
+	Edges []Edge
+}
+
+// Build links the requirements of packages to the packages providing them.
+// Requirements on modules outside the registry are ignored.
+func Build(packages []*hugo.Package) *Graph {
+	byPath := map[string]*hugo.Package{}
+	paths := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		byPath[pkg.ImportPath] = pkg
+		paths = append(paths, pkg.ImportPath)
+	}
+
+	g := &Graph{}
+	for _, pkg := range packages {
+		for _, req := range pkg.Requires {
+			owner := Owner(req.Path, paths)
+			if owner == "" || owner == pkg.ImportPath {
+				continue
+			}
+			g.Edges = append(g.Edges, Edge{Dependent: pkg, Dependency: byPath[owner], Required: req.Version})
+		}
+	}
+
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Dependency.ImportPath != b.Dependency.ImportPath {
+			return a.Dependency.ImportPath < b.Dependency.ImportPath
+		}
+		return a.Dependent.ImportPath < b.Dependent.ImportPath
+	})
+
+	return g
+}
+
+// Dependents returns the edges into the module with the given import path.
+func (g *Graph) Dependents(importPath string) []Edge {
+	var edges []Edge
+	for _, e := range g.Edges {
+		if e.Dependency.ImportPath == importPath {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Owner returns the import path in paths that provides modulePath: the path
+// itself or the longest path it is nested under. It returns "" when no
+// registry module matches.
+func Owner(modulePath string, paths []string) string {
+	owner := ""
+	for _, p := range paths {
+		if (modulePath == p || strings.HasPrefix(modulePath, p+"/")) && len(p) > len(owner) {
+			owner = p
+		}
+	}
+	return owner
+}
@@ -0,0 +1,109 @@
+package depgraph
+
+import (
+	"reflect"
+	"testing"
+
+	"go.ngs.io/internal/hugo"
+)
+
+func TestOwner(t *testing.T) {
+	paths := []string{"go.ngs.io/widget", "go.ngs.io/widget/v2", "go.example.com/tool", "go.example.com/tool/plugin"}
+	tests := []struct {
+		modulePath string
+		want       string
+	}{
+		{"go.ngs.io/widget", "go.ngs.io/widget"},
+		{"go.ngs.io/widget/v2", "go.ngs.io/widget/v2"},
+		// A nested module without its own page belongs to the enclosing one
+		{"go.ngs.io/widget/extra", "go.ngs.io/widget"},
+		// The longest match wins across domains as well
+		{"go.example.com/tool/plugin/v3", "go.example.com/tool/plugin"},
+		{"go.ngs.io/widgets", ""},
+		{"github.com/spf13/pflag", ""},
+	}
+	for _, tt := range tests {
+		if got := Owner(tt.modulePath, paths); got != tt.want {
+			t.Errorf("Owner(%q) = %q, want %q", tt.modulePath, got, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	widget := &hugo.Package{ImportPath: "go.ngs.io/widget", Version: "v1.2.0"}
+	gadget := &hugo.Package{ImportPath: "go.ngs.io/gadget", Requires: []hugo.Requirement{
+		{Path: "go.ngs.io/widget", Version: "v1.1.0"},
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5"},
+	}}
+	tool := &hugo.Package{ImportPath: "go.example.com/tool", Version: "v0.3.0", Requires: []hugo.Requirement{
+		{Path: "go.ngs.io/widget", Version: "v1.2.0"},
+		{Path: "go.ngs.io/gadget/extra", Version: "v0.1.0"},
+		{Path: "go.example.com/tool", Version: "v0.2.0"},
+	}}
+	unreleased := &hugo.Package{ImportPath: "go.ngs.io/unreleased"}
+	app := &hugo.Package{ImportPath: "go.ngs.io/app", Requires: []hugo.Requirement{
+		{Path: "go.example.com/tool", Version: "v0.2.0"},
+		{Path: "go.ngs.io/unreleased", Version: "v0.0.0-20240101000000-abcdefabcdef"},
+	}}
+
+	g := Build([]*hugo.Package{widget, gadget, tool, unreleased, app})
+
+	tests := []struct {
+		dependency string
+		want       []Edge
+		lagging    []bool
+	}{
+		{
+			dependency: widget.ImportPath,
+			want:       []Edge{{tool, widget, "v1.2.0"}, {gadget, widget, "v1.1.0"}},
+			lagging:    []bool{false, true},
+		},
+		{
+			// Nested module paths count as the module holding them
+			dependency: gadget.ImportPath,
+			want:       []Edge{{tool, gadget, "v0.1.0"}},
+			lagging:    []bool{false},
+		},
+		{
+			// Requirements across domains, but not on the module itself
+			dependency: tool.ImportPath,
+			want:       []Edge{{app, tool, "v0.2.0"}},
+			lagging:    []bool{true},
+		},
+		{
+			dependency: unreleased.ImportPath,
+			want:       []Edge{{app, unreleased, "v0.0.0-20240101000000-abcdefabcdef"}},
+			lagging:    []bool{false},
+		},
+		{dependency: app.ImportPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dependency, func(t *testing.T) {
+			edges := g.Dependents(tt.dependency)
+			if !reflect.DeepEqual(edges, tt.want) {
+				t.Fatalf("Dependents = %v, want %v", edges, tt.want)
+			}
+			for i, e := range edges {
+				if got := e.Lagging(); got != tt.lagging[i] {
+					t.Errorf("Lagging of %s = %v, want %v", e.Dependent.ImportPath, got, tt.lagging[i])
+				}
+			}
+		})
+	}
+
+	// Edges are sorted by dependency, then dependent
+	var order []string
+	for _, e := range g.Edges {
+		order = append(order, e.Dependency.ImportPath+" <- "+e.Dependent.ImportPath)
+	}
+	want := []string{
+		"go.example.com/tool <- go.ngs.io/app",
+		"go.ngs.io/gadget <- go.example.com/tool",
+		"go.ngs.io/unreleased <- go.ngs.io/app",
+		"go.ngs.io/widget <- go.example.com/tool",
+		"go.ngs.io/widget <- go.ngs.io/gadget",
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("edges =\n%v\nwant\n%v", order, want)
+	}
+}
@@ -0,0 +1,269 @@
+// Package doctor checks the package pages of the registry for consistency
+// and fixes the problems that have a single safe answer.
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/license"
+	"golang.org/x/mod/semver"
+)
+
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Rule is a consistency check on package pages.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Key         string // Frontmatter key findings point at
+	Fixable     bool
+}
+
+// Rules are the checks run by Check, in report order.
+var Rules = []Rule{
+	{ID: "unreadable", Description: "Package page cannot be parsed", Severity: Error},
+	{ID: "missing-repo-url", Description: "repo_url is missing", Severity: Error, Key: "repo_url"},
+	{ID: "import-path-domain", Description: "import_path is not served by the domain of the content directory", Severity: Error, Key: "import_path"},
+	{ID: "duplicate-import-path", Description: "import_path is declared by another page", Severity: Error, Key: "import_path"},
+	{ID: "documentation-url", Description: "documentation_url is missing or a pkg.go.dev page of another import path", Severity: Warning, Key: "documentation_url", Fixable: true},
+	{ID: "version-semver", Description: "version is not a canonical semantic version", Severity: Warning, Key: "version", Fixable: true},
+	{ID: "license-spdx", Description: "license is not a valid SPDX license expression", Severity: Warning, Key: "license", Fixable: true},
+	{ID: "duplicate-title", Description: "title is used by another package of the domain", Severity: Warning, Key: "title"},
+	{ID: "filename-title", Description: "file name does not match title", Severity: Warning, Key: "title"},
+}
+
+// LookupRule returns the rule with the given ID.
+func LookupRule(id string) Rule {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return Rule{ID: id, Severity: Error}
+}
+
+// Finding is a rule violation in a package page.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"` // Line of the frontmatter key; 0 if unknown
+	Message  string   `json:"message"`
+	Fixed    bool     `json:"fixed,omitempty"`
+}
+
+// Page is a package page of a domain.
+type Page struct {
+	Domain  string
+	Path    string
+	Package *hugo.Package // nil if the page cannot be read
+	Err     error
+	data    []byte
+}
+
+// Name returns the package name: the page file name.
+func (p *Page) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.Path), ".md")
+}
+
+// line returns the line of a top-level frontmatter key, or 0.
+func (p *Page) line(key string) int {
+	if key == "" {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(p.data))
+	for n := 1; scanner.Scan(); n++ {
+		if strings.HasPrefix(scanner.Text(), key+":") {
+			return n
+		}
+		if n > 1 && scanner.Text() == "---" {
+			break // End of frontmatter
+		}
+	}
+	return 0
+}
+
+// Load reads the package pages of domains. Pages that cannot be parsed are
+// returned with their error, for the unreadable rule.
+func Load(domains []hugo.Domain) ([]*Page, error) {
+	var pages []*Page
+	for _, domain := range domains {
+		files, err := hugo.ListPackages(domain.ContentDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range files {
+			page := &Page{Domain: domain.Name, Path: path}
+			if page.data, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			page.Package, page.Err = hugo.ReadPackage(path)
+			pages = append(pages, page)
+		}
+	}
+	return pages, nil
+}
+
+// Check runs the rules over pages. domainOf returns the domain serving an
+// import path, or "" if none does.
+func Check(pages []*Page, domainOf func(importPath string) string) []Finding {
+	var findings []Finding
+	report := func(p *Page, rule, format string, args ...interface{}) {
+		r := LookupRule(rule)
+		findings = append(findings, Finding{
+			Rule:     rule,
+			Severity: r.Severity,
+			File:     p.Path,
+			Line:     p.line(r.Key),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	importPaths := map[string]*Page{}
+	titles := map[string]*Page{}
+	for _, p := range pages {
+		if p.Err != nil {
+			findings = append(findings, unreadable(p))
+			continue
+		}
+		pkg := p.Package
+
+		if pkg.RepoURL == "" {
+			report(p, "missing-repo-url", "repo_url is missing")
+		}
+
+		if domain := domainOf(pkg.ImportPath); domain != p.Domain {
+			if domain == "" {
+				report(p, "import-path-domain", "import path %s is not under any configured domain", pkg.ImportPath)
+			} else {
+				report(p, "import-path-domain", "import path %s belongs to %s, but the page is in the content of %s", pkg.ImportPath, domain, p.Domain)
+			}
+		}
+		if other, ok := importPaths[pkg.ImportPath]; ok {
+			report(p, "duplicate-import-path", "import path %s is also declared by %s", pkg.ImportPath, other.Path)
+		} else {
+			importPaths[pkg.ImportPath] = p
+		}
+
+		if wrongDocumentationURL(pkg) {
+			report(p, "documentation-url", "documentation_url is %q, expected %s", pkg.DocumentationURL, documentationURL(pkg.ImportPath))
+		}
+
+		if pkg.Version != "" && !isCanonical(pkg.Version) {
+			report(p, "version-semver", "version %q is not a canonical semantic version", pkg.Version)
+		}
+
+		if pkg.License != "" {
+			if err := license.CheckExpression(pkg.License); err != nil {
+				report(p, "license-spdx", "license %q: %v", pkg.License, err)
+			}
+		}
+
+		titleKey := p.Domain + "\x00" + strings.ToLower(pkg.Title)
+		if other, ok := titles[titleKey]; ok {
+			report(p, "duplicate-title", "title %q is also used by %s", pkg.Title, other.Path)
+		} else {
+			titles[titleKey] = p
+		}
+
+		if pkg.Title != p.Name() {
+			report(p, "filename-title", "file name %s.md does not match title %q", p.Name(), pkg.Title)
+		}
+	}
+	return findings
+}
+
+// unreadable returns the finding of a page that cannot be parsed, at the
+// file and line of a *hugo.FrontmatterError, which may be a localized page.
+func unreadable(p *Page) Finding {
+	f := Finding{Rule: "unreadable", Severity: LookupRule("unreadable").Severity, File: p.Path, Message: p.Err.Error()}
+	var pageErr *hugo.FrontmatterError
+	if errors.As(p.Err, &pageErr) {
+		if pageErr.File != "" {
+			f.File = pageErr.File
+		}
+		f.Line = pageErr.Line
+		f.Message = "failed to parse frontmatter: " + pageErr.Message
+	}
+	return f
+}
+
+// Fix applies the safe fixes to a package page and returns the IDs of the
+// rules it fixed. The page is not written.
+func Fix(p *Page) []string {
+	if p.Err != nil {
+		return nil
+	}
+	pkg := p.Package
+	var fixed []string
+
+	if wrongDocumentationURL(pkg) {
+		pkg.DocumentationURL = documentationURL(pkg.ImportPath)
+		fixed = append(fixed, "documentation-url")
+	}
+
+	// A version is only rewritten to a tag in the version history
+	if pkg.Version != "" && !isCanonical(pkg.Version) {
+		version := canonicalVersion(pkg.Version)
+		for _, v := range pkg.Versions {
+			if version != "" && v.Version == version {
+				pkg.Version = version
+				fixed = append(fixed, "version-semver")
+				break
+			}
+		}
+	}
+
+	if license.CheckExpression(pkg.License) != nil {
+		if id, ok := license.Canonical(pkg.License); ok {
+			pkg.License = id
+			fixed = append(fixed, "license-spdx")
+		}
+	}
+
+	return fixed
+}
+
+func documentationURL(importPath string) string {
+	return "https://pkg.go.dev/" + importPath
+}
+
+// wrongDocumentationURL reports whether the documentation URL is missing or
+// a pkg.go.dev page of another import path. Other documentation sites are
+// left alone.
+func wrongDocumentationURL(pkg *hugo.Package) bool {
+	return pkg.DocumentationURL == "" ||
+		strings.HasPrefix(pkg.DocumentationURL, "https://pkg.go.dev/") && pkg.DocumentationURL != documentationURL(pkg.ImportPath)
+}
+
+// isCanonical reports whether version is a module version as the go command
+// writes it, e.g. v1.2.0 or v2.0.0+incompatible.
+func isCanonical(version string) bool {
+	return semver.IsValid(version) && semver.Canonical(version) == strings.TrimSuffix(version, "+incompatible")
+}
+
+// canonicalVersion returns the canonical form of a version written without
+// the v prefix or with a shortened or build suffix, e.g. v1.2.0 for 1.2, or
+// "" if there is none.
+func canonicalVersion(version string) string {
+	if version == "" {
+		return ""
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return semver.Canonical(version)
+}
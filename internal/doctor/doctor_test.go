@@ -0,0 +1,176 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.ngs.io/internal/hugo"
+)
+
+// loadPages writes pages into content directories of go.ngs.io and
+// go.example.com, keyed by domain/<file>, and loads them.
+func loadPages(t *testing.T, pages map[string]string) []*Page {
+	t.Helper()
+	root := t.TempDir()
+	domains := []hugo.Domain{
+		{Name: "go.ngs.io", ContentDir: filepath.Join(root, "go.ngs.io")},
+		{Name: "go.example.com", ContentDir: filepath.Join(root, "go.example.com")},
+	}
+	for _, d := range domains {
+		if err := os.MkdirAll(d.ContentDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded, err := Load(domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func domainOf(importPath string) string {
+	for _, domain := range []string{"go.ngs.io", "go.example.com"} {
+		if strings.HasPrefix(importPath, domain+"/") {
+			return domain
+		}
+	}
+	return ""
+}
+
+// page returns the frontmatter of a package page without problems, with
+// extra lines appended.
+func page(name, importPath string, extra ...string) string {
+	lines := []string{
+		"---",
+		"title: " + name,
+		"import_path: " + importPath,
+		"repo_url: https://github.com/ngs/" + name,
+		"documentation_url: https://pkg.go.dev/" + importPath,
+	}
+	lines = append(lines, extra...)
+	return strings.Join(append(lines, "---", "# "+name, ""), "\n")
+}
+
+// summary returns the findings as rule:file:line, with file relative to the
+// content directories.
+func summary(findings []Finding) []string {
+	var lines []string
+	for _, f := range findings {
+		file := filepath.Base(filepath.Dir(f.File)) + "/" + filepath.Base(f.File)
+		lines = append(lines, f.Rule+":"+file+":"+strconv.Itoa(f.Line))
+	}
+	return lines
+}
+
+func TestCheckClean(t *testing.T) {
+	pages := loadPages(t, map[string]string{
+		"go.ngs.io/widget.md":      page("widget", "go.ngs.io/widget", "version: v1.2.0", "license: MIT OR Apache-2.0"),
+		"go.example.com/gadget.md": page("gadget", "go.example.com/gadget"),
+	})
+	if findings := Check(pages, domainOf); len(findings) > 0 {
+		t.Errorf("Check = %v, want no findings", findings)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	pages := loadPages(t, map[string]string{
+		// Lines: 1 ---, 2 title, 3 import_path, 4 repo_url, 5 documentation_url
+		"go.ngs.io/docs-url.md":    strings.Replace(page("docs-url", "go.ngs.io/docs-url"), "pkg.go.dev/go.ngs.io/docs-url", "pkg.go.dev/go.ngs.io/other", 1),
+		"go.ngs.io/semver.md":      page("semver", "go.ngs.io/semver", "version: 1.2"),
+		"go.ngs.io/spdx.md":        page("spdx", "go.ngs.io/spdx", "license: mit"),
+		"go.ngs.io/first.md":       page("first", "go.ngs.io/shared"),
+		"go.ngs.io/second.md":      page("second", "go.ngs.io/shared"),
+		"go.ngs.io/renamed.md":     page("Widget", "go.ngs.io/renamed"),
+		"go.ngs.io/foreign.md":     page("foreign", "go.example.com/foreign"),
+		"go.ngs.io/elsewhere.md":   page("elsewhere", "example.org/elsewhere"),
+		"go.ngs.io/no-repo.md":     "---\ntitle: no-repo\nimport_path: go.ngs.io/no-repo\ndocumentation_url: https://pkg.go.dev/go.ngs.io/no-repo\n---\n",
+		"go.example.com/widget.md": page("widget", "go.example.com/widget"),
+		"go.example.com/other.md":  page("Widget", "go.example.com/other"),
+	})
+
+	got := summary(Check(pages, domainOf))
+	want := []string{
+		"documentation-url:go.ngs.io/docs-url.md:5",
+		"import-path-domain:go.ngs.io/elsewhere.md:3",
+		"import-path-domain:go.ngs.io/foreign.md:3",
+		"missing-repo-url:go.ngs.io/no-repo.md:0",
+		"filename-title:go.ngs.io/renamed.md:2",
+		"duplicate-import-path:go.ngs.io/second.md:3",
+		"version-semver:go.ngs.io/semver.md:6",
+		"license-spdx:go.ngs.io/spdx.md:6",
+		"filename-title:go.example.com/other.md:2",
+		"duplicate-title:go.example.com/widget.md:2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckUnreadable(t *testing.T) {
+	pages := loadPages(t, map[string]string{
+		"go.ngs.io/broken.md":    "---\ntitle: broken\nimport_path: go.ngs.io/broken\ndescription: a: b\n---\n",
+		"go.ngs.io/localized.md": page("localized", "go.ngs.io/localized"),
+		"go.ngs.io/localized.ja.md": "---\ntitle: localized\n" +
+			"description: a: b\n---\n",
+	})
+
+	findings := Check(pages, domainOf)
+	if got, want := summary(findings), []string{"unreadable:go.ngs.io/broken.md:4", "unreadable:go.ngs.io/localized.ja.md:3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Check = %v, want %v", got, want)
+	}
+	if findings[0].Severity != Error || !strings.Contains(findings[0].Message, "mapping values are not allowed") {
+		t.Errorf("finding = %+v", findings[0])
+	}
+}
+
+func TestFix(t *testing.T) {
+	pages := loadPages(t, map[string]string{
+		"go.ngs.io/widget.md": strings.Replace(page("widget", "go.ngs.io/widget",
+			"version: 1.2",
+			"license: apache-2.0",
+			"versions:",
+			"  - version: v1.2.0",
+			"    date: 2024-01-01T00:00:00Z",
+		), "documentation_url: https://pkg.go.dev/go.ngs.io/widget", "documentation_url: https://pkg.go.dev/go.ngs.io/old", 1),
+		// No tag to rewrite the version to; a license expression no ID fixes
+		"go.ngs.io/gadget.md": page("gadget", "go.ngs.io/gadget", "version: 2.0", "license: MIT AND Proprietary"),
+		// Other documentation sites are kept
+		"go.ngs.io/tool.md":   strings.Replace(page("tool", "go.ngs.io/tool"), "https://pkg.go.dev/go.ngs.io/tool", "https://tool.example.com/docs", 1),
+		"go.ngs.io/broken.md": "---\ntitle: broken\ndescription: a: b\n---\n",
+	})
+
+	fixed := map[string][]string{}
+	for _, p := range pages {
+		if rules := Fix(p); len(rules) > 0 {
+			fixed[p.Name()] = rules
+		}
+	}
+	if want := map[string][]string{"widget": {"documentation-url", "version-semver", "license-spdx"}}; !reflect.DeepEqual(fixed, want) {
+		t.Errorf("Fix = %v, want %v", fixed, want)
+	}
+
+	for _, p := range pages {
+		if p.Name() != "widget" {
+			continue
+		}
+		pkg := p.Package
+		if pkg.DocumentationURL != "https://pkg.go.dev/go.ngs.io/widget" || pkg.Version != "v1.2.0" || pkg.License != "Apache-2.0" {
+			t.Errorf("fixed widget = %q, %q, %q", pkg.DocumentationURL, pkg.Version, pkg.License)
+		}
+	}
+
+	got := summary(Check(pages, domainOf))
+	want := []string{"unreadable:go.ngs.io/broken.md:3", "version-semver:go.ngs.io/gadget.md:6", "license-spdx:go.ngs.io/gadget.md:7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check after Fix = %v, want %v", got, want)
+	}
+}
@@ -4,20 +4,28 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	gh "github.com/cli/go-gh/v2"
+	"golang.org/x/mod/semver"
 )
 
 type Repository struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	License     *License   `json:"license"`
-	Topics      []string   `json:"topics"`
-	Owner       Owner      `json:"owner"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	DefaultBranch string    `json:"default_branch"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	License       *License  `json:"license"`
+	Topics        []string  `json:"topics"`
+	Owner         Owner     `json:"owner"`
+	HTMLURL       string    `json:"html_url"`
+	Fork          bool      `json:"fork"`
+	Archived      bool      `json:"archived"`
 }
 
 type License struct {
@@ -32,11 +40,33 @@ type Owner struct {
 }
 
 type Release struct {
-	TagName string `json:"tag_name"`
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
 }
 
 type Tag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// Version is a semantic version tag together with its release metadata.
+type Version struct {
+	Name        string
+	Date        time.Time
+	ReleaseName string
+	Prerelease  bool
+}
+
+type DirEntry struct {
 	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // "file" or "dir"
 }
 
 type Readme struct {
@@ -69,6 +99,57 @@ func GetReadme(owner, repo string) (string, error) {
 	return string(content), nil
 }
 
+// GetFile returns the content of a file in the repository at ref. An empty
+// ref selects the default branch.
+func GetFile(owner, repo, path, ref string) ([]byte, error) {
+	endpoint := fmt.Sprintf("repos/%s/%s/contents/%s", owner, repo, path)
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	stdout, _, err := gh.Exec("api", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+
+	var file Readme
+	if err := json.Unmarshal(stdout.Bytes(), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s data: %w", path, err)
+	}
+
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected encoding: %s", file.Encoding)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+// ListDir lists a directory of the repository at ref. An empty path lists
+// the repository root and an empty ref selects the default branch.
+func ListDir(owner, repo, path, ref string) ([]DirEntry, error) {
+	endpoint := fmt.Sprintf("repos/%s/%s/contents/%s", owner, repo, path)
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	stdout, _, err := gh.Exec("api", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", path, err)
+	}
+
+	var entries []DirEntry
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse directory listing: %w", err)
+	}
+
+	return entries, nil
+}
+
 func GetRepository(owner, repo string) (*Repository, error) {
 	args := []string{"api", fmt.Sprintf("repos/%s/%s", owner, repo)}
 	
@@ -85,6 +166,27 @@ func GetRepository(owner, repo string) (*Repository, error) {
 	return &repository, nil
 }
 
+// ListRepositories returns the public repositories of a user or
+// organization.
+func ListRepositories(owner string) ([]Repository, error) {
+	stdout, _, err := gh.Exec("api", "--paginate", fmt.Sprintf("users/%s/repos?per_page=100&type=owner", owner))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	pages, err := decodePages[[]Repository](&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repositories: %w", err)
+	}
+
+	var repositories []Repository
+	for _, page := range pages {
+		repositories = append(repositories, page...)
+	}
+
+	return repositories, nil
+}
+
 func GetLatestVersion(owner, repo string) (string, error) {
 	// Try to get latest release first
 	args := []string{"api", fmt.Sprintf("repos/%s/%s/releases/latest", owner, repo)}
@@ -117,6 +219,140 @@ func GetLatestVersion(owner, repo string) (string, error) {
 	return "", nil
 }
 
+// ListVersions returns all semantic version tags of a repository, newest
+// first. Release names and dates are taken from published releases; tags
+// without a release are dated by their commit.
+func ListVersions(owner, repo string) ([]Version, error) {
+	stdout, _, err := gh.Exec("api", "--paginate", fmt.Sprintf("repos/%s/%s/releases?per_page=100", owner, repo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+
+	releases, err := decodePages[[]Release](&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	releasesByTag := map[string]Release{}
+	for _, page := range releases {
+		for _, release := range page {
+			if !release.Draft {
+				releasesByTag[release.TagName] = release
+			}
+		}
+	}
+
+	// The GraphQL API returns the commit dates with the tags, which the
+	// REST API needs one request per tag for
+	stdout, _, err = gh.Exec("api", "graphql", "--paginate",
+		"-f", "query="+tagsQuery, "-f", "owner="+owner, "-f", "name="+repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	pages, err := decodePages[tagsPage](&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tags: %w", err)
+	}
+
+	versions := []Version{}
+	for _, page := range pages {
+		for _, tag := range page.Data.Repository.Refs.Nodes {
+			if !semver.IsValid(tag.Name) {
+				continue
+			}
+
+			version := Version{
+				Name:       tag.Name,
+				Date:       tag.commitDate(),
+				Prerelease: semver.Prerelease(tag.Name) != "",
+			}
+
+			if release, ok := releasesByTag[tag.Name]; ok {
+				version.Date = release.PublishedAt
+				version.ReleaseName = release.Name
+				version.Prerelease = version.Prerelease || release.Prerelease
+			}
+
+			versions = append(versions, version)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i].Name, versions[j].Name) > 0
+	})
+
+	return versions, nil
+}
+
+// tagsQuery lists the tags of a repository with the dates of the commits
+// they point at, directly or through an annotated tag. gh fills in
+// $endCursor when paginating.
+const tagsQuery = `query($owner: String!, $name: String!, $endCursor: String) {
+  repository(owner: $owner, name: $name) {
+    refs(refPrefix: "refs/tags/", first: 100, after: $endCursor) {
+      nodes {
+        name
+        target {
+          ... on Commit { committedDate }
+          ... on Tag { target { ... on Commit { committedDate } } }
+        }
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// tagsPage is a page of the tagsQuery result.
+type tagsPage struct {
+	Data struct {
+		Repository struct {
+			Refs struct {
+				Nodes []tagRef `json:"nodes"`
+			} `json:"refs"`
+		} `json:"repository"`
+	} `json:"data"`
+}
+
+type tagRef struct {
+	Name   string `json:"name"`
+	Target struct {
+		CommittedDate time.Time `json:"committedDate"` // Lightweight tags
+		Target        struct {
+			CommittedDate time.Time `json:"committedDate"` // Annotated tags
+		} `json:"target"`
+	} `json:"target"`
+}
+
+// commitDate returns the date of the tagged commit.
+func (t tagRef) commitDate() time.Time {
+	if !t.Target.CommittedDate.IsZero() {
+		return t.Target.CommittedDate
+	}
+	return t.Target.Target.CommittedDate
+}
+
+// decodePages decodes the output of gh api --paginate, which prints one JSON
+// value per page.
+func decodePages[T any](r io.Reader) ([]T, error) {
+	var pages []T
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var page T
+		if err := decoder.Decode(&page); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// ReleaseURL returns the page for the given tag. GitHub serves it for plain
+// tags as well as published releases.
+func ReleaseURL(owner, repo, tag string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s", owner, repo, tag)
+}
+
 func ParseRepoURL(url string) (owner, repo string, err error) {
 	// Parse GitHub URL formats:
 	// https://github.com/owner/repo
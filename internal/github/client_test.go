@@ -0,0 +1,49 @@
+package github
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTagPages(t *testing.T) {
+	// gh api graphql --paginate prints the pages one after another
+	output := `{"data":{"repository":{"refs":{"nodes":[
+		{"name":"v1.0.0","target":{"target":{"committedDate":"2024-01-02T03:04:05Z"}}},
+		{"name":"v1.1.0","target":{"committedDate":"2024-02-03T04:05:06+09:00"}}
+	],"pageInfo":{"hasNextPage":true,"endCursor":"MTAw"}}}}}
+{"data":{"repository":{"refs":{"nodes":[
+		{"name":"v2.0.0","target":{}}
+	],"pageInfo":{"hasNextPage":false,"endCursor":"MTAx"}}}}}
+`
+	pages, err := decodePages[tagsPage](strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dates := map[string]time.Time{}
+	for _, page := range pages {
+		for _, tag := range page.Data.Repository.Refs.Nodes {
+			dates[tag.Name] = tag.commitDate()
+		}
+	}
+	want := map[string]string{
+		"v1.0.0": "2024-01-02T03:04:05Z", // Annotated
+		"v1.1.0": "2024-02-02T19:05:06Z", // Lightweight
+		"v2.0.0": "0001-01-01T00:00:00Z", // Not a commit
+	}
+	if len(dates) != len(want) {
+		t.Fatalf("decoded %d tags, want %d", len(dates), len(want))
+	}
+	for name, date := range want {
+		if got := dates[name].UTC().Format(time.RFC3339); got != date {
+			t.Errorf("date of %s = %s, want %s", name, got, date)
+		}
+	}
+}
+
+func TestDecodePagesInvalid(t *testing.T) {
+	if _, err := decodePages[[]Release](strings.NewReader(`[{"tag_name":"v1.0.0"}] {`)); err == nil {
+		t.Error("decodePages accepted a truncated page")
+	}
+}
@@ -0,0 +1,87 @@
+package godoc
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// Example is a runnable example of a module with its code formatted the way
+// it appears in documentation.
+type Example struct {
+	Name   string // What the example documents, e.g. "Canvas.Draw (nil)"
+	Doc    string
+	Code   string
+	Output string
+}
+
+// Examples returns the Example functions of every package of the module.
+// Package examples are named after the package.
+func (m *Module) Examples() []Example {
+	var examples []Example
+	for _, pkg := range m.Packages {
+		prefix := strings.TrimSuffix(m.anchorPrefix(pkg.ImportPath), ".")
+		add := func(name string, exs []*doc.Example) {
+			if name == "" {
+				name = pkg.Doc.Name
+			} else if prefix != "" {
+				name = prefix + "." + name
+			}
+			for _, ex := range exs {
+				example := Example{
+					Name:   name,
+					Doc:    strings.TrimSpace(ex.Doc),
+					Code:   exampleCode(pkg.Fset, ex),
+					Output: ex.Output,
+				}
+				if ex.Suffix != "" {
+					example.Name += " (" + ex.Suffix + ")"
+				}
+				examples = append(examples, example)
+			}
+		}
+
+		p := pkg.Doc
+		add("", p.Examples)
+		for _, f := range p.Funcs {
+			add(f.Name, f.Examples)
+		}
+		for _, t := range p.Types {
+			add(t.Name, t.Examples)
+			for _, f := range t.Funcs {
+				add(f.Name, f.Examples)
+			}
+			for _, f := range t.Methods {
+				add(t.Name+"."+f.Name, f.Examples)
+			}
+		}
+	}
+	return examples
+}
+
+// exampleCode prints the body of an example function without braces and
+// the output comment, which is shown separately.
+func exampleCode(fset *token.FileSet, ex *doc.Example) string {
+	var comments []*ast.CommentGroup
+	for _, group := range ex.Comments {
+		text := strings.TrimSpace(group.Text())
+		if ex.Output != "" && (strings.HasPrefix(text, "Output:") || strings.HasPrefix(text, "Unordered output:")) {
+			continue
+		}
+		comments = append(comments, group)
+	}
+
+	code := format(fset, &printer.CommentedNode{Node: ex.Code, Comments: comments})
+	if _, ok := ex.Code.(*ast.BlockStmt); !ok {
+		return code
+	}
+
+	code = strings.TrimSuffix(strings.TrimPrefix(code, "{"), "}")
+	lines := strings.Split(strings.Trim(code, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
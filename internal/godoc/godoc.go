@@ -0,0 +1,176 @@
+// Package godoc extracts API documentation of a module from its source with
+// go/parser and go/doc, and renders it as HTML for the site.
+package godoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"go.ngs.io/internal/source"
+)
+
+// Module is the documentation of every importable package of a module.
+type Module struct {
+	Path     string
+	Version  string
+	RepoURL  string
+	Packages []*Package
+}
+
+// Package is the documentation of a single package. Dir is relative to the
+// module root, "" for the root package.
+type Package struct {
+	ImportPath string
+	Dir        string
+	Doc        *doc.Package
+	Fset       *token.FileSet
+}
+
+// IsCommand reports whether the package is a main package.
+func (p *Package) IsCommand() bool {
+	return p.Doc.Name == "main"
+}
+
+// skippedDirs are never documented: they hold no importable packages.
+var skippedDirs = map[string]bool{"testdata": true, "vendor": true, "internal": true}
+
+// Load reads the packages of a module from src at ref. Directories holding a
+// nested module, test data, vendored code or internal packages are skipped.
+// Files are selected with the build constraints of linux/amd64.
+func Load(src source.Source, repoURL, ref, modulePath string) (*Module, error) {
+	return load(src, repoURL, ref, modulePath, false)
+}
+
+// LoadExamples reads like Load only the packages with _test.go files, the
+// only ones that can hold Example functions. The files of other directories
+// are not fetched, which saves a request per file with remote sources.
+func LoadExamples(src source.Source, repoURL, ref, modulePath string) (*Module, error) {
+	return load(src, repoURL, ref, modulePath, true)
+}
+
+func load(src source.Source, repoURL, ref, modulePath string, tested bool) (*Module, error) {
+	module := &Module{Path: modulePath, Version: ref, RepoURL: repoURL}
+	if err := module.loadDir(src, "", tested); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(module.Packages, func(i, j int) bool {
+		return module.Packages[i].ImportPath < module.Packages[j].ImportPath
+	})
+
+	return module, nil
+}
+
+// loadDir reads the package in dir and those below it. With tested, only
+// the files of directories with _test.go files are read.
+func (m *Module) loadDir(src source.Source, dir string, tested bool) error {
+	entries, err := src.ListDir(m.RepoURL, dir, m.Version)
+	if err != nil {
+		return err
+	}
+
+	hasTests := false
+	for _, entry := range entries {
+		if !entry.Dir && strings.HasSuffix(entry.Name, "_test.go") {
+			hasTests = true
+		}
+	}
+
+	var subdirs []string
+	files := map[string][]byte{}
+	for _, entry := range entries {
+		if entry.Dir {
+			if !skippedDirs[entry.Name] && !strings.HasPrefix(entry.Name, ".") && !strings.HasPrefix(entry.Name, "_") {
+				subdirs = append(subdirs, entry.Path)
+			}
+			continue
+		}
+		if dir != "" && entry.Name == "go.mod" {
+			return nil // Nested module
+		}
+		if !strings.HasSuffix(entry.Name, ".go") || tested && !hasTests {
+			continue
+		}
+
+		content, err := src.File(m.RepoURL, entry.Path, m.Version)
+		if err != nil {
+			return err
+		}
+		files[entry.Name] = content
+	}
+
+	if len(files) > 0 {
+		pkg, err := m.parsePackage(dir, files)
+		if err != nil {
+			return err
+		}
+		if pkg != nil {
+			m.Packages = append(m.Packages, pkg)
+		}
+	}
+
+	for _, subdir := range subdirs {
+		if err := m.loadDir(src, subdir, tested); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parsePackage builds the documentation of a directory from its Go files.
+// It returns nil when the directory has no buildable non-test files.
+func (m *Module) parsePackage(dir string, files map[string][]byte) (*Package, error) {
+	ctxt := build.Default
+	ctxt.GOOS = "linux"
+	ctxt.GOARCH = "amd64"
+	ctxt.CgoEnabled = true
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(files[path.Base(name)])), nil
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var astFiles []*ast.File
+	hasSources := false
+	for _, name := range names {
+		if match, err := ctxt.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		// Positions are relative to the repository root for source links
+		f, err := parser.ParseFile(fset, path.Join(dir, name), files[name], parser.ParseComments)
+		if err != nil {
+			continue // Broken files are skipped like go doc does
+		}
+		astFiles = append(astFiles, f)
+		if !strings.HasSuffix(name, "_test.go") {
+			hasSources = true
+		}
+	}
+	if !hasSources {
+		return nil, nil
+	}
+
+	importPath := path.Join(m.Path, dir)
+	docPkg, err := doc.NewFromFiles(fset, astFiles, importPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documentation of %s: %w", importPath, err)
+	}
+
+	return &Package{ImportPath: importPath, Dir: dir, Doc: docPkg, Fset: fset}, nil
+}
@@ -0,0 +1,219 @@
+package godoc
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.ngs.io/internal/source"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files of testdata")
+
+// memSource serves the files of a repository from memory, keyed by their
+// slash-separated path, at any ref.
+type memSource map[string]string
+
+func (s memSource) Name() string { return "memory" }
+
+func (s memSource) Repository(repoURL string) (*source.Repository, error) {
+	return &source.Repository{DefaultBranch: "main"}, nil
+}
+
+func (s memSource) Versions(repoURL string) ([]source.Version, error) { return nil, nil }
+
+func (s memSource) LatestVersion(repoURL string) (string, error) { return "", nil }
+
+func (s memSource) Readme(repoURL string) (string, error) { return s["README.md"], nil }
+
+func (s memSource) ReleaseURL(repoURL, tag string) string { return "" }
+
+func (s memSource) File(repoURL, filePath, ref string) ([]byte, error) {
+	content, ok := s[filePath]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", filePath, fs.ErrNotExist)
+	}
+	return []byte(content), nil
+}
+
+func (s memSource) ListDir(repoURL, dirPath, ref string) ([]source.DirEntry, error) {
+	prefix := ""
+	if dirPath != "" {
+		prefix = dirPath + "/"
+	}
+	seen := map[string]bool{}
+	var entries []source.DirEntry
+	for filePath := range s {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
+		}
+		name, _, dir := strings.Cut(rest, "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entries = append(entries, source.DirEntry{Name: name, Path: path.Join(dirPath, name), Dir: dir})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// widget is a module with a documented root package, a command and
+// directories Load skips.
+var widget = memSource{
+	"go.mod":    "module go.ngs.io/widget\n",
+	"README.md": "# Widget\n",
+	"widget.go": `// Package widget renders widgets.
+//
+// # Drawing
+//
+// Use [New] and [Widget.Draw], or [go.ngs.io/widget/shape.Circle] and
+// [io.Writer].
+package widget
+
+import "io"
+
+// DefaultSize is the size of a widget created by New.
+const DefaultSize = 10
+
+// ErrClosed is returned after Close.
+var ErrClosed = errorString("widget: closed")
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+// Widget draws itself.
+type Widget struct {
+	Size int
+}
+
+// New returns a widget of DefaultSize.
+func New() *Widget { return &Widget{Size: DefaultSize} }
+
+// Draw writes the widget to w.
+func (w *Widget) Draw(out io.Writer) error {
+	_, err := io.WriteString(out, "<widget>")
+	return err
+}
+
+// Render draws a widget of the given size.
+func Render(out io.Writer, size int) error {
+	return (&Widget{Size: size}).Draw(out)
+}
+`,
+	"widget_windows.go": "package widget\n\n// Console is only built on Windows.\nfunc Console() {}\n",
+	"example_test.go": `package widget_test
+
+import (
+	"os"
+
+	"go.ngs.io/widget"
+)
+
+func Example() {
+	widget.Render(os.Stdout, 1)
+	// Output: <widget>
+}
+
+// A widget draws itself once.
+func ExampleWidget_Draw() {
+	widget.New().Draw(os.Stdout)
+	// Output: <widget>
+}
+`,
+	"shape/shape.go":      "// Package shape defines shapes.\npackage shape\n\n// Circle is round.\ntype Circle struct{ R float64 }\n",
+	"cmd/widget/main.go":  "// Command widget draws a widget.\npackage main\n\nfunc main() {}\n",
+	"internal/draw/d.go":  "package draw\n\nfunc Line() {}\n",
+	"testdata/fixture.go": "package fixture\n",
+	"vendor/x/x.go":       "package x\n",
+	"plugin/go.mod":       "module go.ngs.io/widget/plugin\n",
+	"plugin/plugin.go":    "package plugin\n",
+	".github/gen/gen.go":  "package gen\n",
+	"_old/old.go":         "package old\n",
+	"generate/gen.go":     "//go:build ignore\n\npackage main\n",
+	"broken/broken.go":    "package broken\n\nfunc {\n",
+	"docs/README.md":      "# Docs\n",
+	"onlytests/x_test.go": "package onlytests\n",
+}
+
+func TestLoad(t *testing.T) {
+	module, err := Load(widget, "https://github.com/ngs/widget", "v1.0.0", "go.ngs.io/widget")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var importPaths []string
+	for _, pkg := range module.Packages {
+		importPaths = append(importPaths, pkg.ImportPath)
+	}
+	want := []string{"go.ngs.io/widget", "go.ngs.io/widget/cmd/widget", "go.ngs.io/widget/shape"}
+	if !reflect.DeepEqual(importPaths, want) {
+		t.Errorf("packages = %v, want %v", importPaths, want)
+	}
+
+	root := module.Packages[0]
+	if root.Dir != "" || root.IsCommand() || !module.Packages[1].IsCommand() {
+		t.Errorf("root package in %q, commands %v and %v", root.Dir, root.IsCommand(), module.Packages[1].IsCommand())
+	}
+	var funcs []string
+	for _, f := range root.Doc.Funcs {
+		funcs = append(funcs, f.Name)
+	}
+	// Console is built on Windows only
+	if want := []string{"Render"}; !reflect.DeepEqual(funcs, want) {
+		t.Errorf("functions = %v, want %v", funcs, want)
+	}
+}
+
+// TestRender compares the documentation of widget with
+// testdata/widget.golden. Run with -update to rewrite the golden file.
+func TestRender(t *testing.T) {
+	module, err := Load(widget, "https://github.com/ngs/widget", "v1.0.0", "go.ngs.io/widget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := module.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	golden := filepath.Join("testdata", "widget.golden")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("Render differs from %s:\n%s", golden, got)
+	}
+
+	for _, fragment := range []string{
+		`<section class="docs-package" id="pkg-overview">`,
+		`<div class="decl" id="Widget.Draw">`,
+		`<a class="source-link" href="https://github.com/ngs/widget/blob/v1.0.0/widget.go#L30">source</a>`,
+		`<details class="example" id="example-Widget.Draw">`,
+		`<a href="#shape.Circle">go.ngs.io/widget/shape.Circle</a>`,
+		`<a href="https://pkg.go.dev/io#Writer">io.Writer</a>`,
+		`<h4 id="hdr-Drawing">Drawing</h4>`,
+	} {
+		if !strings.Contains(got, fragment) {
+			t.Errorf("Render lacks %s", fragment)
+		}
+	}
+}
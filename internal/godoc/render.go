@@ -0,0 +1,340 @@
+package godoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"go/doc/comment"
+	"go/printer"
+	"go/token"
+	"html/template"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// pkgsiteURL documents packages outside the module.
+const pkgsiteURL = "https://pkg.go.dev"
+
+// section is a rendered declaration: a constant or variable group, a
+// function, a type or a method.
+type section struct {
+	ID       string
+	Name     string
+	Decl     string
+	Doc      template.HTML
+	Source   string
+	Examples []example
+	Children []section // Constants, variables, constructors and methods of a type
+}
+
+type example struct {
+	ID     string
+	Name   string
+	Doc    template.HTML
+	Code   string
+	Output string
+}
+
+type packageView struct {
+	ID         string
+	ImportPath string
+	Name       string
+	Synopsis   string
+	Command    bool
+	Overview   template.HTML
+	Examples   []example
+	Constants  []section
+	Variables  []section
+	Functions  []section
+	Types      []section
+}
+
+var moduleTemplate = template.Must(template.New("module").Parse(`
+{{- define "section" -}}
+<div class="decl" id="{{ .ID }}">
+<h4><a href="#{{ .ID }}">{{ if .Name }}{{ .Name }}{{ else }}&para;{{ end }}</a>{{ with .Source }} <a class="source-link" href="{{ . }}">source</a>{{ end }}</h4>
+<pre><code>{{ .Decl }}</code></pre>
+{{ .Doc }}
+{{- range .Examples }}{{ template "example" . }}{{ end }}
+{{- range .Children }}{{ template "section" . }}{{ end }}
+</div>
+{{ end -}}
+{{- define "example" -}}
+<details class="example" id="{{ .ID }}">
+<summary>Example{{ with .Name }} ({{ . }}){{ end }}</summary>
+{{ .Doc }}
+<pre><code>{{ .Code }}</code></pre>
+{{- with .Output }}
+<p>Output:</p>
+<pre><code>{{ . }}</code></pre>
+{{- end }}
+</details>
+{{ end -}}
+{{- if gt (len .) 1 }}
+<nav class="docs-index">
+<h2>Packages</h2>
+<ul>
+{{- range . }}
+<li><a href="#{{ .ID }}">{{ .ImportPath }}</a>{{ with .Synopsis }} &mdash; {{ . }}{{ end }}</li>
+{{- end }}
+</ul>
+</nav>
+{{- end }}
+{{ range . -}}
+<section class="docs-package" id="{{ .ID }}">
+<h2>{{ if .Command }}Command{{ else }}Package {{ .Name }}{{ end }} <code>{{ .ImportPath }}</code></h2>
+{{ .Overview }}
+{{- range .Examples }}{{ template "example" . }}{{ end }}
+{{- if not .Command }}
+{{- with .Constants }}
+<h3>Constants</h3>
+{{ range . }}{{ template "section" . }}{{ end }}
+{{- end }}
+{{- with .Variables }}
+<h3>Variables</h3>
+{{ range . }}{{ template "section" . }}{{ end }}
+{{- end }}
+{{- with .Functions }}
+<h3>Functions</h3>
+{{ range . }}{{ template "section" . }}{{ end }}
+{{- end }}
+{{- with .Types }}
+<h3>Types</h3>
+{{ range . }}{{ template "section" . }}{{ end }}
+{{- end }}
+{{- end }}
+</section>
+{{ end -}}
+`))
+
+// Render writes the HTML documentation of the module. Declarations link to
+// their source at the module version in the repository blob view.
+func (m *Module) Render(w io.Writer) error {
+	views := make([]packageView, 0, len(m.Packages))
+	for _, pkg := range m.Packages {
+		views = append(views, m.packageView(pkg))
+	}
+
+	if err := moduleTemplate.Execute(w, views); err != nil {
+		return fmt.Errorf("failed to render documentation of %s: %w", m.Path, err)
+	}
+	return nil
+}
+
+// renderer renders the declarations of one package.
+type renderer struct {
+	module  *Module
+	pkg     *Package
+	prefix  string // Anchor prefix, empty for the root package
+	printer *comment.Printer
+}
+
+func (m *Module) packageView(pkg *Package) packageView {
+	r := &renderer{module: m, pkg: pkg, prefix: m.anchorPrefix(pkg.ImportPath)}
+	r.printer = pkg.Doc.Printer()
+	r.printer.HeadingLevel = 4
+	r.printer.HeadingID = func(h *comment.Heading) string {
+		return r.prefix + "hdr-" + headingID(h)
+	}
+	r.printer.DocLinkURL = r.docLinkURL
+
+	p := pkg.Doc
+	view := packageView{
+		ID:         "pkg-" + strings.TrimSuffix(r.prefix, "."),
+		ImportPath: pkg.ImportPath,
+		Name:       p.Name,
+		Synopsis:   p.Synopsis(p.Doc),
+		Command:    pkg.IsCommand(),
+		Overview:   r.html(p.Doc),
+		Examples:   r.examples("", p.Examples),
+	}
+	if r.prefix == "" {
+		view.ID = "pkg-overview"
+	}
+
+	for _, v := range p.Consts {
+		view.Constants = append(view.Constants, r.valueSection(v))
+	}
+	for _, v := range p.Vars {
+		view.Variables = append(view.Variables, r.valueSection(v))
+	}
+	for _, f := range p.Funcs {
+		view.Functions = append(view.Functions, r.funcSection(f, ""))
+	}
+	for _, t := range p.Types {
+		typ := section{
+			ID:       r.prefix + t.Name,
+			Name:     "type " + t.Name,
+			Decl:     r.format(t.Decl),
+			Doc:      r.html(t.Doc),
+			Source:   r.sourceURL(t.Decl.Pos()),
+			Examples: r.examples(t.Name, t.Examples),
+		}
+		for _, v := range t.Consts {
+			typ.Children = append(typ.Children, r.valueSection(v))
+		}
+		for _, v := range t.Vars {
+			typ.Children = append(typ.Children, r.valueSection(v))
+		}
+		for _, f := range t.Funcs {
+			typ.Children = append(typ.Children, r.funcSection(f, ""))
+		}
+		for _, f := range t.Methods {
+			typ.Children = append(typ.Children, r.funcSection(f, t.Name))
+		}
+		view.Types = append(view.Types, typ)
+	}
+
+	return view
+}
+
+// headingID derives an anchor from a doc comment heading the way go doc
+// does, replacing everything but letters and digits with underscores.
+func headingID(h *comment.Heading) string {
+	var b strings.Builder
+	for _, text := range h.Text {
+		if plain, ok := text.(comment.Plain); ok {
+			b.WriteString(string(plain))
+		}
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, b.String())
+}
+
+func (r *renderer) valueSection(v *doc.Value) section {
+	s := section{
+		Decl:   r.format(v.Decl),
+		Doc:    r.html(v.Doc),
+		Source: r.sourceURL(v.Decl.Pos()),
+	}
+	if len(v.Names) > 0 {
+		s.ID = r.prefix + v.Names[0]
+	}
+	return s
+}
+
+func (r *renderer) funcSection(f *doc.Func, recv string) section {
+	id, name := f.Name, "func "+f.Name
+	if recv != "" {
+		id = recv + "." + f.Name
+		name = "func (" + f.Recv + ") " + f.Name
+	}
+	return section{
+		ID:       r.prefix + id,
+		Name:     name,
+		Decl:     r.format(f.Decl),
+		Doc:      r.html(f.Doc),
+		Source:   r.sourceURL(f.Decl.Pos()),
+		Examples: r.examples(id, f.Examples),
+	}
+}
+
+func (r *renderer) examples(parent string, examples []*doc.Example) []example {
+	var out []example
+	for _, ex := range examples {
+		id := "example-" + r.prefix + parent
+		if ex.Suffix != "" {
+			id += "-" + ex.Suffix
+		}
+		out = append(out, example{
+			ID:     strings.TrimSuffix(id, "-"),
+			Name:   ex.Suffix,
+			Doc:    r.html(ex.Doc),
+			Code:   exampleCode(r.pkg.Fset, ex),
+			Output: ex.Output,
+		})
+	}
+	return out
+}
+
+func (r *renderer) format(node interface{}) string {
+	return format(r.pkg.Fset, node)
+}
+
+func format(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := config.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func (r *renderer) html(text string) template.HTML {
+	if text == "" {
+		return ""
+	}
+	// The comment printer escapes the text it renders
+	return template.HTML(r.printer.HTML(r.pkg.Doc.Parser().Parse(text)))
+}
+
+// docLinkURL points doc links at anchors of this page for packages of the
+// module and at pkg.go.dev for others.
+func (r *renderer) docLinkURL(link *comment.DocLink) string {
+	name := link.Name
+	if link.Recv != "" {
+		name = link.Recv + "." + name
+	}
+
+	if link.ImportPath == "" {
+		return "#" + r.prefix + name
+	}
+	if r.module.hasPackage(link.ImportPath) {
+		prefix := r.module.anchorPrefix(link.ImportPath)
+		if name == "" {
+			if prefix == "" {
+				return "#pkg-overview"
+			}
+			return "#pkg-" + strings.TrimSuffix(prefix, ".")
+		}
+		return "#" + prefix + name
+	}
+
+	url := pkgsiteURL + "/" + link.ImportPath
+	if name != "" {
+		url += "#" + name
+	}
+	return url
+}
+
+func (r *renderer) sourceURL(pos token.Pos) string {
+	position := r.pkg.Fset.Position(pos)
+	if !position.IsValid() {
+		return ""
+	}
+	return r.module.SourceURL(position.Filename, position.Line)
+}
+
+// SourceURL links a line of a file at the module version, using the blob
+// layout also advertised by the go-source meta tag.
+func (m *Module) SourceURL(file string, line int) string {
+	ref := m.Version
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return fmt.Sprintf("%s/blob/%s/%s#L%d", strings.TrimSuffix(m.RepoURL, "/"), ref, file, line)
+}
+
+// anchorPrefix returns the prefix of anchors of a package: empty for the
+// module root and the relative directory with dashes for slashes followed
+// by a dot otherwise, e.g. "cmd-draw.".
+func (m *Module) anchorPrefix(importPath string) string {
+	if importPath == m.Path {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(importPath, m.Path+"/"), "/", "-") + "."
+}
+
+func (m *Module) hasPackage(importPath string) bool {
+	for _, pkg := range m.Packages {
+		if pkg.ImportPath == importPath {
+			return true
+		}
+	}
+	return false
+}
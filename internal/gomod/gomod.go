@@ -0,0 +1,99 @@
+// Package gomod extracts the go.mod directives the site cares about.
+package gomod
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+type File struct {
+	Path       string
+	Deprecated string // Message of a "// Deprecated:" module comment
+	Go         string // Minimum Go version, e.g. "1.22"
+	Toolchain  string // Suggested toolchain, e.g. "go1.23.1"
+	Require    []Requirement
+	Retract    []Retraction
+}
+
+// Requirement is a required module version. Indirect requirements are
+// marked with an "// indirect" comment.
+type Requirement struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// Retraction is a retracted version interval. Low and High are equal for a
+// single retracted version.
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// Parse parses a go.mod file. Files the strict parser rejects, e.g. with
+// directives newer than golang.org/x/mod knows, are parsed leniently, which
+// drops the toolchain directive.
+func Parse(data []byte) (*File, error) {
+	mf, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		mf, err = modfile.ParseLax("go.mod", data, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	f := &File{}
+	if mf.Module != nil {
+		f.Path = mf.Module.Mod.Path
+		f.Deprecated = mf.Module.Deprecated
+	}
+	if mf.Go != nil {
+		f.Go = mf.Go.Version
+	}
+	if mf.Toolchain != nil {
+		f.Toolchain = mf.Toolchain.Name
+	}
+
+	for _, r := range mf.Require {
+		f.Require = append(f.Require, Requirement{
+			Path:     r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		})
+	}
+
+	for _, r := range mf.Retract {
+		f.Retract = append(f.Retract, Retraction{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+
+	return f, nil
+}
+
+// DirectDependencies returns the number of requirements not marked
+// indirect.
+func (f *File) DirectDependencies() int {
+	count := 0
+	for _, r := range f.Require {
+		if !r.Indirect {
+			count++
+		}
+	}
+	return count
+}
+
+// Retracted reports whether version falls in any retracted interval.
+func (f *File) Retracted(version string) bool {
+	for _, r := range f.Retract {
+		if semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0 {
+			return true
+		}
+	}
+	return false
+}
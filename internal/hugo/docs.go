@@ -0,0 +1,23 @@
+package hugo
+
+// DocsSection is the content section, under the content directory, where
+// vanity generate docs writes API documentation pages. The pages are
+// generated at deploy time and not committed.
+const DocsSection = "docs"
+
+// DocsPage is the API documentation page of a package, rendered as HTML
+// content at /docs/<package>/.
+type DocsPage struct {
+	Title   string `yaml:"title"`
+	Package string `yaml:"package"` // Name of the package page
+	Module  string `yaml:"module"`
+	Version string `yaml:"version,omitempty"`
+	RepoURL string `yaml:"repo_url"`
+	Body    string `yaml:"-"` // Rendered HTML
+}
+
+// WriteDocsPage writes a documentation page. filePath should have the .html
+// extension so Hugo does not process the body as Markdown.
+func WriteDocsPage(filePath string, page *DocsPage) error {
+	return writePage(filePath, page, page.Body)
+}
@@ -2,9 +2,13 @@ package hugo
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,17 +16,102 @@ import (
 )
 
 type Package struct {
-	Title            string    `yaml:"title"`
-	ImportPath       string    `yaml:"import_path"`
-	RepoURL          string    `yaml:"repo_url"`
-	Description      string    `yaml:"description"`
-	Version          string    `yaml:"version"`
-	DocumentationURL string    `yaml:"documentation_url"`
-	License          string    `yaml:"license"`
-	Author           string    `yaml:"author"`
-	CreatedAt        time.Time `yaml:"created_at"`
-	UpdatedAt        time.Time `yaml:"updated_at"`
-	Body             string    `yaml:"-"` // Content after frontmatter (README)
+	Title            string        `yaml:"title"`
+	ImportPath       string        `yaml:"import_path"`
+	RepoURL          string        `yaml:"repo_url"`
+	DefaultBranch    string        `yaml:"default_branch,omitempty"`
+	Description      string        `yaml:"description"`
+	Version          string        `yaml:"version"`
+	DocumentationURL string        `yaml:"documentation_url"`
+	License          string        `yaml:"license"`
+	LicenseNote      string        `yaml:"license_note,omitempty"` // Set when the license was detected heuristically
+	Author           string        `yaml:"author"`
+	CreatedAt        time.Time     `yaml:"created_at"`
+	UpdatedAt        time.Time     `yaml:"updated_at"`
+	Deprecated       string        `yaml:"deprecated,omitempty"`   // go.mod "// Deprecated:" message
+	Retired          bool          `yaml:"retired,omitempty"`      // Stub kept only to serve the import path
+	GoVersion        string        `yaml:"go_version,omitempty"`   // go.mod go directive
+	Toolchain        string        `yaml:"toolchain,omitempty"`    // go.mod toolchain directive
+	Dependencies     int           `yaml:"dependencies,omitempty"` // Number of direct requirements
+	Requires         []Requirement `yaml:"requires,omitempty"`     // Requirements on other registry modules
+	Commands         []string      `yaml:"commands,omitempty"`     // Import paths of installable main packages
+	UsageFile        string        `yaml:"usage_file,omitempty"`   // Repository file the usage text is read from
+	Usage            string        `yaml:"usage,omitempty"`        // --help style usage text
+	Examples         []Example     `yaml:"examples,omitempty"`     // Example functions from _test.go files
+	Versions         []Version     `yaml:"versions,omitempty"`
+	Aliases          []string      `yaml:"aliases,omitempty"`       // Former page URLs Hugo redirects here
+	MirrorAssets     bool          `yaml:"mirror_assets,omitempty"` // README images are served from static/assets/
+	Body             string        `yaml:"-"`                       // Content after frontmatter (README)
+
+	// Localizations are the translated pages keyed by language, stored next
+	// to the package page as <name>.<lang>.md.
+	Localizations map[string]*Localization `yaml:"-"`
+}
+
+// Stub returns the page kept for a retired package: enough for the go-import
+// and go-source tags to keep resolving the import path, with notice shown as
+// its deprecation message.
+func (p *Package) Stub(notice string) *Package {
+	return &Package{
+		Title:            p.Title,
+		ImportPath:       p.ImportPath,
+		RepoURL:          p.RepoURL,
+		DefaultBranch:    p.DefaultBranch,
+		Description:      p.Description,
+		Version:          p.Version,
+		DocumentationURL: p.DocumentationURL,
+		License:          p.License,
+		Author:           p.Author,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Deprecated:       notice,
+		Retired:          true,
+	}
+}
+
+// Languages are the site languages besides the default one. They must match
+// the [languages] table of hugo.toml.
+var Languages = []string{"ja"}
+
+// Localization is the language-specific content of a package page. An empty
+// Description falls back to the package description.
+type Localization struct {
+	Description string
+	Body        string // Localized README, e.g. README.ja.md
+}
+
+// Version is an entry in the version history shown on the package page.
+type Version struct {
+	Version    string    `yaml:"version"`
+	Date       time.Time `yaml:"date"`
+	Name       string    `yaml:"name,omitempty"`
+	Prerelease bool      `yaml:"prerelease,omitempty"`
+	Retracted  bool      `yaml:"retracted,omitempty"`
+}
+
+// Requirement is a go.mod requirement on another module of the registry.
+type Requirement struct {
+	Path    string `yaml:"path"`
+	Version string `yaml:"version"`
+}
+
+// Example is a runnable Example function shown on the package page.
+type Example struct {
+	Name   string `yaml:"name"` // What the example documents, e.g. "Canvas.Draw (nil)"
+	Doc    string `yaml:"doc,omitempty"`
+	Code   string `yaml:"code"`
+	Output string `yaml:"output,omitempty"`
+}
+
+// IsLibrary reports whether the module root is an importable package, i.e.
+// it is not itself one of the installable commands.
+func (p *Package) IsLibrary() bool {
+	for _, command := range p.Commands {
+		if command == p.ImportPath {
+			return false
+		}
+	}
+	return true
 }
 
 func ReadPackage(filePath string) (*Package, error) {
@@ -34,44 +123,183 @@ func ReadPackage(filePath string) (*Package, error) {
 	// Extract frontmatter and body
 	frontmatter, body, err := extractFrontmatterAndBody(data)
 	if err != nil {
-		return nil, err
+		return nil, &FrontmatterError{File: filePath, Line: 1, Message: err.Error()}
 	}
 
 	var pkg Package
-	if err := yaml.Unmarshal(frontmatter, &pkg); err != nil {
-		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	if err := ParseFrontmatter(filePath, frontmatter, &pkg); err != nil {
+		return nil, err
 	}
 
 	pkg.Body = body
 
+	// Read localized pages
+	for _, lang := range Languages {
+		localized, err := readLocalization(LocalizedPath(filePath, lang), pkg.Description)
+		if err != nil {
+			return nil, err
+		}
+		if localized != nil {
+			if pkg.Localizations == nil {
+				pkg.Localizations = map[string]*Localization{}
+			}
+			pkg.Localizations[lang] = localized
+		}
+	}
+
 	return &pkg, nil
 }
 
+func readLocalization(filePath, description string) (*Localization, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	frontmatter, body, err := extractFrontmatterAndBody(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, &FrontmatterError{File: filePath, Line: 1, Message: err.Error()})
+	}
+
+	var localized Package
+	if err := ParseFrontmatter(filePath, frontmatter, &localized); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	localization := &Localization{Body: body}
+	if localized.Description != description {
+		localization.Description = localized.Description
+	}
+	return localization, nil
+}
+
+// LocalizedPath returns the path of the page of a package in the given
+// language, e.g. content/koikoi.ja.md for content/koikoi.md.
+func LocalizedPath(filePath, lang string) string {
+	return strings.TrimSuffix(filePath, ".md") + "." + lang + ".md"
+}
+
+// WritePackage writes the package page and its localized pages. Localized
+// pages of languages missing from pkg.Localizations are removed.
 func WritePackage(filePath string, pkg *Package) error {
-	// Marshal package to YAML
+	if err := writePage(filePath, pkg, pkg.Body); err != nil {
+		return err
+	}
+
+	for _, lang := range Languages {
+		localizedPath := LocalizedPath(filePath, lang)
+		localization, ok := pkg.Localizations[lang]
+		if !ok {
+			if err := os.Remove(localizedPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", localizedPath, err)
+			}
+			continue
+		}
+
+		// Localized pages repeat the metadata the templates render
+		localized := *pkg
+		localized.Body = localization.Body
+		if localization.Description != "" {
+			localized.Description = localization.Description
+		}
+		if err := writePage(localizedPath, &localized, localized.Body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemovePackage removes the package page and its localized pages. It returns
+// the removed files.
+func RemovePackage(filePath string) ([]string, error) {
+	paths := []string{filePath}
+	for _, lang := range Languages {
+		paths = append(paths, LocalizedPath(filePath, lang))
+	}
+
+	var removed []string
+	for _, path := range paths {
+		err := os.Remove(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// writePage writes a content file with the YAML frontmatter of page.
+func writePage(filePath string, page interface{}, body string) error {
+	// Marshal page to YAML
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(pkg); err != nil {
+	if err := encoder.Encode(page); err != nil {
 		return fmt.Errorf("failed to encode package: %w", err)
 	}
 
 	// Create markdown content with frontmatter and body
 	var content string
-	if pkg.Body != "" {
-		content = fmt.Sprintf("---\n%s---\n\n%s", buf.String(), pkg.Body)
+	if body != "" {
+		content = fmt.Sprintf("---\n%s---\n\n%s", buf.String(), body)
 	} else {
 		content = fmt.Sprintf("---\n%s---\n", buf.String())
 	}
 
+	return writeFile(filePath, []byte(content))
+}
+
+// rename is replaced in tests to fail at the last step of writeFile.
+var rename = os.Rename
+
+// writeFile replaces the file at filePath with data through a temporary
+// file in the same directory, so an interrupted run leaves either the old or
+// the new content, never a truncated file. An existing file keeps its mode;
+// new files are created with 0644.
+func writeFile(filePath string, data []byte) error {
+	// Replace the target of a symlink rather than the link
+	if target, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = target
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Write file
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	// Hidden and without the .md suffix, so a leftover file is not read as a page
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -92,7 +320,7 @@ func ListPackages(contentDir string) ([]string, error) {
 		}
 		
 		name := entry.Name()
-		if strings.HasSuffix(name, ".md") && name != "_index.md" {
+		if strings.HasSuffix(name, ".md") && name != "_index.md" && !isLocalized(name) {
 			packages = append(packages, filepath.Join(contentDir, name))
 		}
 	}
@@ -100,6 +328,117 @@ func ListPackages(contentDir string) ([]string, error) {
 	return packages, nil
 }
 
+// Domain is a vanity domain served from the repository and the content
+// directory of its package pages.
+type Domain struct {
+	Name       string
+	ContentDir string
+}
+
+// PackageFile is a package page and the domain serving it.
+type PackageFile struct {
+	Domain     string
+	Path       string
+	ImportPath string
+	Err        error // Set when the page cannot be read; ImportPath is then empty
+}
+
+// ListDomainPackages returns the package pages of all domains. Pages that
+// cannot be read are listed with their error, so callers can report them and
+// go on with the others. An import path declared by more than one page, in
+// the same or different domains, is an error.
+func ListDomainPackages(domains []Domain) ([]PackageFile, error) {
+	var files []PackageFile
+	declared := map[string]string{}
+	for _, domain := range domains {
+		packageFiles, err := ListPackages(domain.ContentDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, filePath := range packageFiles {
+			pkg, err := ReadPackage(filePath)
+			if err != nil {
+				files = append(files, PackageFile{Domain: domain.Name, Path: filePath, Err: err})
+				continue
+			}
+			if other, ok := declared[pkg.ImportPath]; ok {
+				return nil, fmt.Errorf("import path %s is declared by both %s and %s", pkg.ImportPath, other, filePath)
+			}
+			declared[pkg.ImportPath] = filePath
+
+			files = append(files, PackageFile{
+				Domain:     domain.Name,
+				Path:       filePath,
+				ImportPath: pkg.ImportPath,
+			})
+		}
+	}
+	return files, nil
+}
+
+// ImportPaths returns the import paths of the readable packages of all
+// domains.
+func ImportPaths(domains []Domain) ([]string, error) {
+	files, err := ListDomainPackages(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.Err == nil {
+			paths = append(paths, f.ImportPath)
+		}
+	}
+	return paths, nil
+}
+
+// isLocalized reports whether name is a localized page such as koikoi.ja.md.
+func isLocalized(name string) bool {
+	lang := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(name, ".md")), ".")
+	for _, l := range Languages {
+		if lang == l {
+			return true
+		}
+	}
+	return false
+}
+
+// FrontmatterError is a page whose frontmatter cannot be parsed. Line is the
+// line of the problem in the page file, or 0 when it is unknown.
+type FrontmatterError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *FrontmatterError) Error() string {
+	if e.Line == 0 {
+		return "failed to parse frontmatter: " + e.Message
+	}
+	return fmt.Sprintf("failed to parse frontmatter: line %d: %s", e.Line, e.Message)
+}
+
+// yamlLine matches the line number in yaml.v3 errors.
+var yamlLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ParseFrontmatter decodes the YAML frontmatter of the page at filePath into
+// v. A problem is returned as *FrontmatterError at its line in the page.
+func ParseFrontmatter(filePath string, frontmatter []byte, v interface{}) error {
+	err := yaml.Unmarshal(frontmatter, v)
+	if err == nil {
+		return nil
+	}
+	pageErr := &FrontmatterError{File: filePath, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		pageErr.Line = line + 1 // The frontmatter starts after the --- line
+		pageErr.Message = m[2]
+	}
+	return pageErr
+}
+
 func extractFrontmatterAndBody(data []byte) (frontmatter []byte, body string, err error) {
 	content := string(data)
 
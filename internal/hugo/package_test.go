@@ -0,0 +1,176 @@
+package hugo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteFileFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "widget.md")
+	if err := os.WriteFile(path, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rename = func(string, string) error { return errors.New("interrupted") }
+	defer func() { rename = os.Rename }()
+
+	if err := writeFile(path, []byte("replacement\n")); err == nil {
+		t.Fatal("writeFile succeeded although the rename failed")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "original\n" {
+		t.Errorf("content = %q, want the original", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("directory contains %v, want only widget.md", names)
+	}
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "widget.md")
+	if err := os.WriteFile(path, []byte("original\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0640); err != nil { // Not subject to the umask
+		t.Fatal(err)
+	}
+
+	if err := writeFile(path, []byte("replacement\n")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+
+	created := filepath.Join(dir, "new", "gadget.md")
+	if err := writeFile(created, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(created); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0644 {
+		t.Errorf("mode of a new file = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.md")
+	link := filepath.Join(dir, "link.md")
+	if err := os.WriteFile(target, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip(err)
+	}
+
+	if err := writeFile(link, []byte("replacement\n")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link.md is no longer a symlink")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "replacement\n" {
+		t.Errorf("target content = %q, want the replacement", data)
+	}
+}
+
+func TestReadPackageFrontmatterError(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+	}{
+		{"malformed", "---\ntitle: widget\ndescription: : bad\n---\n", 3},
+		{"type", "---\ntitle: widget\nrequires: text\n---\n", 3},
+		{"missing", "# widget\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "widget.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ReadPackage(path)
+			var pageErr *FrontmatterError
+			if !errors.As(err, &pageErr) {
+				t.Fatalf("ReadPackage returned %v, want *FrontmatterError", err)
+			}
+			if pageErr.File != path || pageErr.Line != tt.line {
+				t.Errorf("error at %s:%d, want %s:%d", pageErr.File, pageErr.Line, path, tt.line)
+			}
+		})
+	}
+}
+
+func TestListDomainPackages(t *testing.T) {
+	primary, secondary := t.TempDir(), t.TempDir()
+	pages := map[string]string{
+		filepath.Join(primary, "widget.md"):    "---\ntitle: widget\nimport_path: go.ngs.io/widget\n---\n",
+		filepath.Join(primary, "widget.ja.md"): "---\ntitle: widget\n---\n",
+		filepath.Join(primary, "broken.md"):    "---\ntitle: broken\ndescription: : bad\n---\n",
+		filepath.Join(secondary, "gadget.md"):  "---\ntitle: gadget\nimport_path: go.example.com/gadget\n---\n",
+	}
+	for path, content := range pages {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	domains := []Domain{{Name: "go.ngs.io", ContentDir: primary}, {Name: "go.example.com", ContentDir: secondary}}
+
+	files, err := ListDomainPackages(domains)
+	if err != nil {
+		t.Fatalf("an unreadable page failed the listing: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("ListDomainPackages = %v, want 3 pages", files)
+	}
+	var pageErr *FrontmatterError
+	if broken := files[0]; broken.Path != filepath.Join(primary, "broken.md") || !errors.As(broken.Err, &pageErr) || broken.ImportPath != "" {
+		t.Errorf("broken page listed as %+v, want its *FrontmatterError", broken)
+	}
+	if widget := files[1]; widget.Err != nil || widget.ImportPath != "go.ngs.io/widget" || widget.Domain != "go.ngs.io" {
+		t.Errorf("widget listed as %+v", widget)
+	}
+	if gadget := files[2]; gadget.Err != nil || gadget.ImportPath != "go.example.com/gadget" || gadget.Domain != "go.example.com" {
+		t.Errorf("gadget listed as %+v", gadget)
+	}
+
+	paths, err := ImportPaths(domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go.ngs.io/widget", "go.example.com/gadget"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ImportPaths = %v, want %v", paths, want)
+	}
+
+	// A duplicate import path stops the listing
+	duplicate := filepath.Join(secondary, "widget.md")
+	if err := os.WriteFile(duplicate, []byte(pages[filepath.Join(primary, "widget.md")]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListDomainPackages(domains); err == nil {
+		t.Errorf("ListDomainPackages accepted go.ngs.io/widget declared twice")
+	}
+}
@@ -0,0 +1,96 @@
+package hugo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReleasesFile is the name of the data file that records the release
+// history of the packages of a domain.
+const ReleasesFile = "releases.yaml"
+
+const (
+	EventAdded   = "added"
+	EventRelease = "release"
+)
+
+// Release is a single entry in the release history: either a newly added
+// package or an observed version transition.
+type Release struct {
+	Event           string    `yaml:"event"`
+	Name            string    `yaml:"name"`
+	ImportPath      string    `yaml:"import_path"`
+	Version         string    `yaml:"version,omitempty"`
+	PreviousVersion string    `yaml:"previous_version,omitempty"`
+	URL             string    `yaml:"url,omitempty"`
+	Date            time.Time `yaml:"date"`
+}
+
+// ReadReleases reads the release history. A missing file yields an empty
+// history.
+func ReadReleases(filePath string) ([]Release, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var releases []Release
+	if err := yaml.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+func WriteReleases(filePath string, releases []Release) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(releases); err != nil {
+		return fmt.Errorf("failed to encode releases: %w", err)
+	}
+
+	return writeFile(filePath, buf.Bytes())
+}
+
+// AppendRelease adds an entry to the release history file.
+func AppendRelease(filePath string, release Release) error {
+	releases, err := ReadReleases(filePath)
+	if err != nil {
+		return err
+	}
+
+	return WriteReleases(filePath, append(releases, release))
+}
+
+// RenameReleases points the entries recorded for the page name in the
+// domains accepted by inDomain at newName, so feed links follow a renamed
+// package. Import paths are history and kept. A history without such entries
+// is left untouched.
+func RenameReleases(filePath, name, newName string, inDomain func(importPath string) bool) error {
+	releases, err := ReadReleases(filePath)
+	if err != nil {
+		return err
+	}
+
+	renamed := false
+	for i := range releases {
+		if releases[i].Name == name && inDomain(releases[i].ImportPath) {
+			releases[i].Name = newName
+			renamed = true
+		}
+	}
+	if !renamed {
+		return nil
+	}
+	return WriteReleases(filePath, releases)
+}
@@ -0,0 +1,128 @@
+// Package license identifies the SPDX license of LICENSE files by comparing
+// them with bundled license templates.
+package license
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ExactConfidence is the score above which a match is treated as the
+	// license itself rather than a heuristic guess.
+	ExactConfidence = 0.9
+	// MinConfidence is the lowest score reported as a match at all.
+	MinConfidence = 0.75
+)
+
+//go:embed templates/*.txt
+var templateFS embed.FS
+
+type template struct {
+	id      string
+	bigrams map[string]int
+	total   int
+}
+
+var templates = loadTemplates()
+
+// Match is the result of classifying a license text.
+type Match struct {
+	ID         string  // SPDX license identifier
+	Confidence float64 // Similarity with the template, from 0 to 1
+}
+
+// Heuristic reports whether the match is an approximation rather than a
+// verbatim copy of the license template.
+func (m Match) Heuristic() bool {
+	return m.Confidence < ExactConfidence
+}
+
+// Classify returns the best matching license of text, if any template
+// scores at least MinConfidence.
+func Classify(text []byte) (Match, bool) {
+	bigrams, total := countBigrams(string(text))
+	if total == 0 {
+		return Match{}, false
+	}
+
+	var best Match
+	for _, t := range templates {
+		common := 0
+		for bigram, n := range bigrams {
+			common += min(n, t.bigrams[bigram])
+		}
+		confidence := 2 * float64(common) / float64(total+t.total)
+		if confidence > best.Confidence {
+			best = Match{ID: t.id, Confidence: confidence}
+		}
+	}
+
+	if best.Confidence < MinConfidence {
+		return Match{}, false
+	}
+	return best, true
+}
+
+// IsLicenseFile reports whether a file name looks like a license file.
+func IsLicenseFile(name string) bool {
+	base := strings.ToUpper(strings.TrimSuffix(name, path.Ext(name)))
+	switch base {
+	case "LICENSE", "LICENCE", "COPYING", "UNLICENSE":
+		return true
+	}
+	return false
+}
+
+// loadTemplates reads the bundled templates. They are embedded in the
+// binary and go:embed fails the build when none match, so reading them
+// cannot fail at run time; a failure is a broken build and panics.
+func loadTemplates() []template {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(fmt.Sprintf("license: reading embedded templates: %v", err))
+	}
+
+	var loaded []template
+	for _, entry := range entries {
+		data, err := templateFS.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("license: reading embedded template %s: %v", entry.Name(), err))
+		}
+		bigrams, total := countBigrams(string(data))
+		loaded = append(loaded, template{
+			id:      strings.TrimSuffix(entry.Name(), ".txt"),
+			bigrams: bigrams,
+			total:   total,
+		})
+	}
+	return loaded
+}
+
+var wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// countBigrams normalizes a license text and counts its word pairs.
+// Copyright lines are dropped since they differ between every project.
+func countBigrams(text string) (map[string]int, int) {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "copyright") {
+			continue
+		}
+		for _, word := range wordPattern.FindAllString(line, -1) {
+			if word == "licence" {
+				word = "license"
+			}
+			words = append(words, word)
+		}
+	}
+
+	bigrams := map[string]int{}
+	for i := 1; i < len(words); i++ {
+		bigrams[words[i-1]+" "+words[i]]++
+	}
+	return bigrams, len(words) - 1
+}
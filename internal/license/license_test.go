@@ -0,0 +1,95 @@
+package license
+
+import (
+	"strings"
+	"testing"
+)
+
+func readTemplate(t *testing.T, id string) string {
+	t.Helper()
+	data, err := templateFS.ReadFile("templates/" + id + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestClassifyTemplates(t *testing.T) {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".txt")
+		t.Run(id, func(t *testing.T) {
+			match, ok := Classify([]byte(readTemplate(t, id)))
+			if !ok || match.ID != id {
+				t.Fatalf("Classify = %+v, %v; want %s", match, ok, id)
+			}
+			if match.Confidence < ExactConfidence || match.Heuristic() {
+				t.Errorf("confidence %.2f is below ExactConfidence", match.Confidence)
+			}
+		})
+	}
+}
+
+func TestClassifyEditedMIT(t *testing.T) {
+	// An MIT license with a preamble and an extra clause added by the author
+	text := strings.Replace(readTemplate(t, "MIT"), "THE SOFTWARE IS PROVIDED",
+		"This software is distributed with the following disclaimer. THE SOFTWARE IS PROVIDED", 1) +
+		"\nAdditional terms: the authors must be credited in the documentation of any product using the Software, " +
+		"and the name of the authors may not be used to endorse derived products.\n"
+
+	match, ok := Classify([]byte(text))
+	if !ok || match.ID != "MIT" {
+		t.Fatalf("Classify = %+v, %v; want MIT", match, ok)
+	}
+	if match.Confidence < MinConfidence || match.Confidence >= ExactConfidence {
+		t.Errorf("confidence = %.2f, want between %.2f and %.2f", match.Confidence, MinConfidence, ExactConfidence)
+	}
+	if !match.Heuristic() {
+		t.Errorf("edited text is not reported as a heuristic match")
+	}
+}
+
+func TestClassifyBSD(t *testing.T) {
+	bsd3 := strings.NewReplacer(
+		"<year>, <copyright holder>", "2024, Acme Inc.",
+		"the name of the copyright holder", "the name of Acme Inc.",
+	).Replace(readTemplate(t, "BSD-3-Clause"))
+	bsd2 := strings.Replace(readTemplate(t, "BSD-2-Clause"), "<year>, <copyright holder>", "2024, Acme Inc.", 1)
+
+	for want, text := range map[string]string{"BSD-3-Clause": bsd3, "BSD-2-Clause": bsd2} {
+		if match, ok := Classify([]byte(text)); !ok || match.ID != want || match.Heuristic() {
+			t.Errorf("Classify = %+v, %v; want %s", match, ok, want)
+		}
+	}
+}
+
+func TestClassifyUnrelated(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"# Widget\n\nWidget renders widgets. Install it with go install go.ngs.io/widget@latest.\n",
+		"All rights reserved. Do not copy, distribute or modify this software without written permission.\n",
+	} {
+		if match, ok := Classify([]byte(text)); ok {
+			t.Errorf("Classify(%q) = %+v, want no match", text, match)
+		}
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"LICENSE":     true,
+		"LICENSE.md":  true,
+		"licence.txt": true,
+		"COPYING":     true,
+		"UNLICENSE":   true,
+		"README.md":   false,
+		"LICENSES":    false,
+	} {
+		if got := IsLicenseFile(name); got != want {
+			t.Errorf("IsLicenseFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
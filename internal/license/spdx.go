@@ -0,0 +1,154 @@
+package license
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+var (
+	//go:embed spdx-licenses.txt
+	licenseList string
+	//go:embed spdx-exceptions.txt
+	exceptionList string
+
+	licenseIDs   = loadIDs(licenseList)
+	exceptionIDs = loadIDs(exceptionList)
+)
+
+// loadIDs indexes the identifiers of a list by their lowercase form.
+func loadIDs(list string) map[string]string {
+	ids := map[string]string{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			ids[strings.ToLower(line)] = line
+		}
+	}
+	return ids
+}
+
+// Canonical returns the SPDX license identifier matching id regardless of
+// case, e.g. "MIT" for "mit".
+func Canonical(id string) (string, bool) {
+	canonical, ok := licenseIDs[strings.ToLower(id)]
+	return canonical, ok
+}
+
+// CheckExpression reports whether expr is a valid SPDX license expression
+// such as "MIT", "Apache-2.0 OR MIT" or "GPL-2.0-or-later WITH
+// Classpath-exception-2.0" over the bundled license list. Identifiers must
+// match in case; LicenseRef- identifiers are accepted as they are.
+func CheckExpression(expr string) error {
+	p := &expressionParser{tokens: tokenize(expr)}
+	if len(p.tokens) == 0 {
+		return fmt.Errorf("empty license expression")
+	}
+	if err := p.expression(); err != nil {
+		return err
+	}
+	if p.pos < len(p.tokens) {
+		return fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return nil
+}
+
+func tokenize(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+// expressionParser parses
+//
+//	expression = term { "OR" term }
+//	term       = factor { "AND" factor }
+//	factor     = "(" expression ")" | license [ "WITH" exception ]
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// operator consumes the operator op, which may be upper or lower case.
+func (p *expressionParser) operator(op string) bool {
+	if token := p.next(); token == op || token == strings.ToLower(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *expressionParser) expression() error {
+	if err := p.term(); err != nil {
+		return err
+	}
+	for p.operator("OR") {
+		if err := p.term(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *expressionParser) term() error {
+	if err := p.factor(); err != nil {
+		return err
+	}
+	for p.operator("AND") {
+		if err := p.factor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *expressionParser) factor() error {
+	token := p.next()
+	switch {
+	case token == "":
+		return fmt.Errorf("license expression ends unexpectedly")
+	case token == "(":
+		p.pos++
+		if err := p.expression(); err != nil {
+			return err
+		}
+		if p.next() != ")" {
+			return fmt.Errorf("missing )")
+		}
+		p.pos++
+		return nil
+	}
+
+	p.pos++
+	if err := checkLicenseID(token); err != nil {
+		return err
+	}
+	if p.operator("WITH") {
+		exception := p.next()
+		if exception == "" || exceptionIDs[strings.ToLower(exception)] != exception {
+			return fmt.Errorf("unknown license exception %q", exception)
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func checkLicenseID(id string) error {
+	if strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-") {
+		return nil
+	}
+	canonical, ok := Canonical(strings.TrimSuffix(id, "+"))
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown license identifier %q", id)
+	case canonical != strings.TrimSuffix(id, "+"):
+		return fmt.Errorf("license identifier %q should be written %q", id, canonical)
+	}
+	return nil
+}
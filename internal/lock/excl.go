@@ -0,0 +1,41 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// The lock file of a process that died stays behind.
+const staleHint = "; remove the lock file if no command is running"
+
+// Acquire takes the lock at path by creating it, failing with *HeldError if
+// it exists.
+func Acquire(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		holder := ""
+		if existing, err := os.Open(path); err == nil {
+			holder = readHolder(existing)
+			existing.Close()
+		}
+		return nil, &HeldError{Path: path, Holder: holder}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	file.WriteString(owner() + "\n")
+	return &Lock{path: path, file: file}, nil
+}
+
+// Release releases the lock by removing the lock file.
+func (l *Lock) Release() error {
+	l.file.Close()
+	if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("failed to release %s: %w", l.path, err)
+	}
+	return nil
+}
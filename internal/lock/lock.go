@@ -0,0 +1,51 @@
+// Package lock serializes the commands that write to a repository, such as
+// a scheduled update running while a package is added by hand. The lock is
+// a file next to the configuration file.
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is the name of the lock file at the repository root.
+const File = ".vanity.lock"
+
+// Path returns the lock file of the repository configured by configFile.
+func Path(configFile string) string {
+	return filepath.Join(filepath.Dir(configFile), File)
+}
+
+// Lock is a held repository lock.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// HeldError reports a lock held by another command.
+type HeldError struct {
+	Path   string
+	Holder string // Process ID and command line of the holder, if recorded
+}
+
+func (e *HeldError) Error() string {
+	holder := "another command"
+	if e.Holder != "" {
+		holder = e.Holder
+	}
+	return fmt.Sprintf("repository is locked by %s (%s); wait for it to finish%s", holder, e.Path, staleHint)
+}
+
+// owner describes the current process for HeldError.
+func owner() string {
+	return fmt.Sprintf("pid %d: %s", os.Getpid(), strings.Join(os.Args, " "))
+}
+
+// readHolder returns the owner recorded in a lock file.
+func readHolder(file *os.File) string {
+	data := make([]byte, 1024)
+	n, _ := file.ReadAt(data, 0)
+	return strings.TrimSpace(string(data[:n]))
+}
@@ -0,0 +1,48 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAcquireWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+
+	held, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Acquire(path)
+	var heldErr *HeldError
+	if !errors.As(err, &heldErr) {
+		t.Fatalf("second Acquire returned %v, want *HeldError", err)
+	}
+	if heldErr.Path != path {
+		t.Errorf("Path = %q, want %q", heldErr.Path, path)
+	}
+	if pid := fmt.Sprintf("pid %d:", os.Getpid()); !strings.HasPrefix(heldErr.Holder, pid) {
+		t.Errorf("Holder = %q, want it to start with %q", heldErr.Holder, pid)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire after Release: %v", err)
+	}
+	if err := again.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	if got, want := Path(filepath.Join("repo", "vanity.yaml")), filepath.Join("repo", File); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+// Package modpath validates package names and import paths before they are
+// turned into content files and go-import tags.
+package modpath
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.ngs.io/internal/hugo"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// namePattern is a lowercase module path element, which is also a portable
+// file name.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// reservedNames are content files or sections a package page must not
+// replace.
+var reservedNames = []string{"index", hugo.DocsSection}
+
+// CheckName reports whether name can name a package: its page is
+// <content>/<name>.md and its default import path <domain>/<name>.
+func CheckName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid package name %q: use lowercase letters, digits, '.', '-' and '_', starting with a letter or digit", name)
+	}
+	if strings.Contains(name, "..") || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid package name %q: dots must separate other characters", name)
+	}
+	if slices.Contains(reservedNames, name) {
+		return fmt.Errorf("invalid package name %q: reserved by the site", name)
+	}
+	for _, lang := range hugo.Languages {
+		// <name>.<lang>.md is a localized page of <name>
+		if strings.HasSuffix(name, "."+lang) {
+			return fmt.Errorf("invalid package name %q: would be read as a localized page", name)
+		}
+	}
+	return nil
+}
+
+// CheckImportPath reports whether importPath is a valid module path served
+// by domain.
+func CheckImportPath(importPath, domain string) error {
+	if err := module.CheckPath(importPath); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(importPath, domain+"/") {
+		return fmt.Errorf("import path %s is not under %s", importPath, domain)
+	}
+	return nil
+}
+
+// CheckVersion reports whether version can be a version of the module at
+// importPath: v2 and later need the matching /vN suffix. Tags that are not
+// semantic versions are not checked.
+func CheckVersion(importPath, version string) error {
+	if !semver.IsValid(version) {
+		return nil
+	}
+	_, pathMajor, ok := module.SplitPathVersion(importPath)
+	if !ok {
+		return fmt.Errorf("invalid import path %s", importPath)
+	}
+	if err := module.CheckPathMajor(version, pathMajor); err != nil {
+		return fmt.Errorf("import path %s does not match its version: %w", importPath, err)
+	}
+	return nil
+}
+
+// Conflict returns the registered import path that importPath collides
+// with: the same path ignoring case, or a path nested in or containing it.
+// Major versions of a module, e.g. go.ngs.io/tool/v2 for go.ngs.io/tool, do
+// not collide. It returns "" when there is none.
+func Conflict(importPath string, registry []string) string {
+	prefix, _, _ := module.SplitPathVersion(importPath)
+	for _, registered := range registry {
+		if strings.EqualFold(registered, importPath) {
+			return registered
+		}
+		if registeredPrefix, _, _ := module.SplitPathVersion(registered); registeredPrefix == prefix {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(importPath), strings.ToLower(registered)+"/") ||
+			strings.HasPrefix(strings.ToLower(registered), strings.ToLower(importPath)+"/") {
+			return registered
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package modpath
+
+import "testing"
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"widget", true},
+		{"go-widget", true},
+		{"widget_2", true},
+		{"widget.v2", true},
+		{"0x", true},
+		{"", false},
+		{"tools/widget", false},
+		{"Widget", false},
+		{"my widget", false},
+		{"..", false},
+		{"widget..v2", false},
+		{"widget.", false},
+		{".widget", false},
+		{"-widget", false},
+		{"index", false},
+		{"docs", false},
+		{"widget.ja", false},
+		{"ja", true},
+	}
+	for _, tt := range tests {
+		if err := CheckName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("CheckName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestCheckImportPath(t *testing.T) {
+	tests := []struct {
+		importPath string
+		valid      bool
+	}{
+		{"go.ngs.io/widget", true},
+		{"go.ngs.io/widget/v2", true},
+		{"go.ngs.io/tools/widget", true},
+		{"go.ngs.io/widget/v1", false},
+		{"go.ngs.io/widget/v0", false},
+		{"go.ngs.io/widget/v2/", false},
+		{"go.ngs.io/Widget", true},
+		{"go.ngs.io/my widget", false},
+		{"go.ngs.io/../widget", false},
+		{"go.ngs.io", false},
+		{"go.ngs.iowidget", false},
+		{"go.example.com/widget", false},
+	}
+	for _, tt := range tests {
+		if err := CheckImportPath(tt.importPath, "go.ngs.io"); (err == nil) != tt.valid {
+			t.Errorf("CheckImportPath(%q) = %v, want valid %v", tt.importPath, err, tt.valid)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		importPath string
+		version    string
+		valid      bool
+	}{
+		{"go.ngs.io/widget", "v1.2.0", true},
+		{"go.ngs.io/widget", "v0.3.0", true},
+		{"go.ngs.io/widget", "v2.0.0", false},
+		{"go.ngs.io/widget", "v2.0.0+incompatible", true},
+		{"go.ngs.io/widget/v2", "v2.1.0", true},
+		{"go.ngs.io/widget/v2", "v1.2.0", false},
+		{"go.ngs.io/widget/v3", "v2.1.0", false},
+		{"go.ngs.io/widget", "", true},
+		{"go.ngs.io/widget", "release-1", true},
+	}
+	for _, tt := range tests {
+		if err := CheckVersion(tt.importPath, tt.version); (err == nil) != tt.valid {
+			t.Errorf("CheckVersion(%q, %q) = %v, want valid %v", tt.importPath, tt.version, err, tt.valid)
+		}
+	}
+}
+
+func TestConflict(t *testing.T) {
+	registry := []string{"go.ngs.io/tool", "go.ngs.io/tools/widget", "go.ngs.io/gadget/v2"}
+	tests := []struct {
+		importPath string
+		conflict   string
+	}{
+		{"go.ngs.io/other", ""},
+		{"go.ngs.io/tool", "go.ngs.io/tool"},
+		{"go.ngs.io/Tool", "go.ngs.io/tool"},
+		{"go.ngs.io/tool/cmd", "go.ngs.io/tool"},
+		{"go.ngs.io/tools", "go.ngs.io/tools/widget"},
+		{"go.ngs.io/toolbox", ""},
+		{"go.ngs.io/tool/v2", ""},
+		{"go.ngs.io/gadget", ""},
+		{"go.ngs.io/gadget/v2", "go.ngs.io/gadget/v2"},
+		{"go.ngs.io/gadget/v2/cmd", "go.ngs.io/gadget/v2"},
+	}
+	for _, tt := range tests {
+		if got := Conflict(tt.importPath, registry); got != tt.conflict {
+			t.Errorf("Conflict(%q) = %q, want %q", tt.importPath, got, tt.conflict)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package readme
+
+import (
+	"regexp"
+	"strings"
+)
+
+// languagePattern matches localized README names such as README.ja.md,
+// README_ja.md or README-zh-CN.md.
+var languagePattern = regexp.MustCompile(`(?i)^readme[._-]([a-z]{2,3}(?:[-_][a-z0-9]{2,4})?)\.(?:md|markdown)$`)
+
+// Language returns the lowercase language tag of a localized README file
+// name, or "" when name is not a localized README.
+func Language(name string) string {
+	m := languagePattern.FindStringSubmatch(name)
+	if m == nil {
+		return ""
+	}
+	return strings.ReplaceAll(strings.ToLower(m[1]), "_", "-")
+}
@@ -0,0 +1,210 @@
+// Package readme post-processes README Markdown before it is stored as the
+// body of a package page.
+package readme
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Links holds the URL prefixes relative README references resolve against:
+// Blob for pages such as docs/usage.md and Raw for images.
+type Links struct {
+	Blob string // e.g. https://github.com/ngs/servedir/blob/main
+	Raw  string // e.g. https://raw.githubusercontent.com/ngs/servedir/main
+}
+
+var (
+	inlineLinkPattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(\s*(<[^>]*>|[^()\s]+)((?:\s+(?:"[^"]*"|'[^']*'))?\s*)\)`)
+	refDefPattern     = regexp.MustCompile(`^(\s{0,3}\[[^\]]+\]:\s*)(<[^>]*>|\S+)(.*)$`)
+	htmlAttrPattern   = regexp.MustCompile(`(?i)(<(img|a|source)\b[^>]*?\s(src|href)\s*=\s*)("[^"]*"|'[^']*')`)
+)
+
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".avif": true,
+}
+
+// RewriteLinks resolves relative links and images in a README against the
+// repository: links point at the blob view and images at the raw content.
+// Absolute URLs, in-page anchors and code are left untouched.
+func RewriteLinks(markdown string, links Links) string {
+	return mapText(markdown, func(text string) string {
+		return mapRefs(text, func(ref string, image bool) string {
+			return resolve(ref, links, image)
+		})
+	})
+}
+
+// MapImages replaces the URL of every image in a README with the result of
+// fn, leaving links and code untouched.
+func MapImages(markdown string, fn func(ref string) string) string {
+	return mapText(markdown, func(text string) string {
+		return mapRefs(text, func(ref string, image bool) string {
+			if !image {
+				return ref
+			}
+			return fn(ref)
+		})
+	})
+}
+
+// mapRefs replaces the targets of Markdown links, images, reference
+// definitions and HTML src/href attributes in text with the result of fn.
+func mapRefs(text string, fn func(ref string, image bool) string) string {
+	if m := refDefPattern.FindStringSubmatch(text); m != nil {
+		target := strings.Trim(m[2], "<>")
+		return m[1] + fn(target, isImagePath(target)) + m[3]
+	}
+
+	text = inlineLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := inlineLinkPattern.FindStringSubmatch(match)
+		image := m[1] == "!"
+		label := mapRefs(m[2], fn) // badges nest images inside links
+		target := strings.Trim(m[3], "<>")
+		return m[1] + "[" + label + "](" + fn(target, image) + m[4] + ")"
+	})
+
+	return htmlAttrPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := htmlAttrPattern.FindStringSubmatch(match)
+		quote := m[4][:1]
+		value := m[4][1 : len(m[4])-1]
+		image := !strings.EqualFold(m[2], "a")
+		return m[1] + quote + fn(value, image) + quote
+	})
+}
+
+// resolve turns a repository-relative reference into an absolute URL.
+func resolve(ref string, links Links, image bool) string {
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" {
+		return ref
+	}
+
+	base := links.Blob
+	if image {
+		base = links.Raw
+	}
+	if base == "" {
+		return ref
+	}
+
+	// Leading slashes are relative to the repository root on GitHub
+	p := path.Clean("/" + strings.TrimPrefix(u.Path, "/"))
+	resolved := strings.TrimSuffix(base, "/") + p
+	if u.RawQuery != "" {
+		resolved += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		resolved += "#" + u.Fragment
+	}
+	return resolved
+}
+
+func isImagePath(ref string) bool {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return false
+	}
+	return imageExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// mapText applies fn to every line of Markdown outside fenced code blocks,
+// skipping inline code spans.
+func mapText(markdown string, fn func(string) string) string {
+	lines := strings.Split(markdown, "\n")
+	fence := ""
+	for i, line := range lines {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if opening, ok := openingFence(line); ok {
+			fence = opening
+			continue
+		}
+		lines[i] = mapOutsideCodeSpans(line, fn)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mapProse applies fn to each run of lines outside fenced code blocks, with
+// inline code spans masked out. Unlike mapText it sees elements spanning
+// several lines.
+func mapProse(markdown string, fn func(string) string) string {
+	lines := strings.Split(markdown, "\n")
+	var out, prose []string
+	flush := func() {
+		if len(prose) > 0 {
+			out = append(out, mapOutsideCodeSpans(strings.Join(prose, "\n"), fn))
+			prose = nil
+		}
+	}
+
+	fence := ""
+	for _, line := range lines {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			out = append(out, line)
+			continue
+		}
+		if opening, ok := openingFence(line); ok {
+			flush()
+			fence = opening
+			out = append(out, line)
+			continue
+		}
+		prose = append(prose, line)
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+// openingFence returns the fence of a line opening a fenced code block as
+// CommonMark defines it: at most three spaces of indentation and at least
+// three backticks or tildes, with no backtick in the info string of a
+// backtick fence. Deeper indented lines are not fences.
+func openingFence(line string) (string, bool) {
+	rest := strings.TrimLeft(line, " ")
+	if len(line)-len(rest) > 3 || rest == "" || rest[0] != '`' && rest[0] != '~' {
+		return "", false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	if n < 3 || rest[0] == '`' && strings.Contains(rest[n:], "`") {
+		return "", false
+	}
+	return rest[:n], true
+}
+
+// closesFence reports whether line closes the code block opened by fence:
+// at most three spaces of indentation and at least as many of the fence
+// characters, followed only by spaces.
+func closesFence(line, fence string) bool {
+	rest := strings.TrimLeft(line, " ")
+	if len(line)-len(rest) > 3 {
+		return false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, fence[:1]))
+	return n >= len(fence) && strings.TrimSpace(rest[n:]) == ""
+}
+
+func mapOutsideCodeSpans(line string, fn func(string) string) string {
+	parts := strings.Split(line, "`")
+	// An odd number of parts means the backticks are balanced
+	if len(parts)%2 == 0 {
+		return fn(line)
+	}
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = fn(parts[i])
+	}
+	return strings.Join(parts, "`")
+}
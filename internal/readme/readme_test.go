@@ -0,0 +1,133 @@
+package readme
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files of testdata")
+
+var testLinks = Links{
+	Blob: "https://github.com/ngs/example/blob/main",
+	Raw:  "https://raw.githubusercontent.com/ngs/example/main",
+}
+
+// process runs a README through the pipeline of refresh.Readme. Links are
+// empty for repositories outside GitHub.
+func process(markdown string, links Links) (string, []string) {
+	body := RewriteLinks(markdown, links)
+	body, changes := Sanitize(body)
+	return Transform(body, "example"), changes
+}
+
+// TestGolden compares the processed READMEs of testdata/*.md, followed by
+// the reported changes, with testdata/*.golden. Only links.md is resolved
+// against a repository, so the others show the sanitizer on its own. Run
+// with -update to rewrite the golden files.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".md")
+		t.Run(name, func(t *testing.T) {
+			markdown, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			links := Links{}
+			if name == "links" {
+				links = testLinks
+			}
+			body, changes := process(string(markdown), links)
+			got := body + "\n-- changes --\n" + strings.Join(changes, "\n") + "\n"
+
+			golden := strings.TrimSuffix(input, ".md") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from %s:\n%s", input, golden, got)
+			}
+		})
+	}
+}
+
+var (
+	// Hugo renders with unsafe = true, so raw HTML reaches the page
+	siteMarkdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithAttribute()),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	scriptPattern       = regexp.MustCompile(`(?i)<(script|iframe|style|object|embed)\b`)
+	eventHandlerPattern = regexp.MustCompile(`(?i)<[a-z][^>]*\son[a-z]+\s*=`)
+	unsafeURLPattern    = regexp.MustCompile(`(?i)\b(href|src|srcset)\s*=\s*["']?\s*(javascript|vbscript|data):`)
+)
+
+// TestRenderedHTMLIsSafe renders every processed README like the site does,
+// with and without repository links, and looks for scripts, event handlers
+// and script URLs in the HTML.
+func TestRenderedHTMLIsSafe(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		markdown, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, links := range []Links{{}, testLinks} {
+			body, _ := process(string(markdown), links)
+
+			var page bytes.Buffer
+			if err := siteMarkdown.Convert([]byte(body), &page); err != nil {
+				t.Fatal(err)
+			}
+			for _, pattern := range []*regexp.Regexp{scriptPattern, eventHandlerPattern, unsafeURLPattern} {
+				if match := pattern.FindString(page.String()); match != "" {
+					t.Errorf("%s renders %q:\n%s", input, match, page.String())
+				}
+			}
+		}
+	}
+}
+
+// TestSanitizeIsStable checks that sanitized READMEs pass unchanged.
+func TestSanitizeIsStable(t *testing.T) {
+	inputs, err := filepath.Glob("testdata/*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		markdown, err := os.ReadFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		once, _ := Sanitize(string(markdown))
+		twice, changes := Sanitize(once)
+		if twice != once || len(changes) > 0 {
+			t.Errorf("%s changes when sanitized again: %v", input, changes)
+		}
+	}
+}
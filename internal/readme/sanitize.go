@@ -0,0 +1,441 @@
+package readme
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/net/html"
+)
+
+// allowedTags are the HTML elements kept in READMEs, close to what GitHub
+// itself renders. Other tags are dropped while their text is kept.
+var allowedTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true, "center": true,
+	"code": true, "dd": true, "del": true, "details": true, "div": true, "dl": true,
+	"dt": true, "em": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "hr": true, "i": true, "img": true, "ins": true, "kbd": true, "li": true,
+	"ol": true, "p": true, "picture": true, "pre": true, "q": true, "s": true, "samp": true,
+	"source": true, "span": true, "strike": true, "strong": true, "sub": true,
+	"summary": true, "sup": true, "table": true, "tbody": true, "td": true, "tfoot": true,
+	"th": true, "thead": true, "tr": true, "tt": true, "u": true, "ul": true, "var": true,
+}
+
+// removedElements are dropped together with their content. They include
+// every element whose content browsers read as raw text.
+var removedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"template": true, "noscript": true, "form": true, "textarea": true, "title": true,
+	"xmp": true, "noembed": true, "noframes": true, "plaintext": true,
+}
+
+var allowedAttrs = map[string]bool{
+	"align": true, "alt": true, "colspan": true, "height": true, "href": true, "id": true,
+	"media": true, "name": true, "open": true, "rowspan": true, "src": true, "srcset": true,
+	"title": true, "type": true, "valign": true, "width": true,
+}
+
+// unsafeSchemes are URL schemes that run code or embed documents.
+var unsafeSchemes = []string{"javascript:", "vbscript:", "data:"}
+
+var (
+	shortcodePattern      = regexp.MustCompile(`\{\{([<%])(.*?)([>%])\}\}`)
+	strayShortcodePattern = regexp.MustCompile(`\{\{([<%])`)
+	destinationPattern    = regexp.MustCompile(`\]\(|\]:|<`)
+)
+
+// markdownParser parses READMEs the way Hugo's default goldmark
+// configuration does, so the HTML it finds is the HTML the site renders.
+var markdownParser = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.DefinitionList, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAttribute()),
+).Parser()
+
+// maxPasses bounds the sanitizer passes over a README.
+const maxPasses = 5
+
+// Sanitize makes a README safe to embed in a Hugo page, which renders raw
+// HTML: shortcode delimiters are escaped so Hugo renders them literally,
+// HTML is reduced to an allowlist of tags and attributes without scripts or
+// event handlers, and links with script URLs lose their destination. It
+// returns the sanitized Markdown and a description of every change.
+func Sanitize(markdown string) (string, []string) {
+	var changes []string
+	report := func(format string, args ...interface{}) {
+		changes = append(changes, fmt.Sprintf(format, args...))
+	}
+
+	// Hugo expands shortcodes even inside code blocks, so escape them
+	// everywhere
+	markdown = shortcodePattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		m := shortcodePattern.FindStringSubmatch(match)
+		if strings.HasPrefix(m[2], "/*") {
+			return match // already escaped
+		}
+		report("escaped shortcode %s", match)
+		return "{{" + m[1] + "/*" + m[2] + "*/" + m[3] + "}}"
+	})
+	markdown = escapeStrayShortcodes(markdown, report)
+
+	// Removing HTML can change how the rest parses, e.g. an HTML block
+	// losing its first tag becomes a paragraph whose links are live, so the
+	// README is sanitized until it no longer changes
+	for i := 0; i < maxPasses; i++ {
+		sanitized := sanitizeMarkdown(markdown, report)
+		if sanitized == markdown {
+			break
+		}
+		markdown = sanitized
+	}
+
+	return markdown, changes
+}
+
+// escapeStrayShortcodes breaks up shortcode openers that have no matching
+// closer with a zero-width space.
+func escapeStrayShortcodes(markdown string, report func(string, ...interface{})) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range strayShortcodePattern.FindAllStringIndex(markdown, -1) {
+		if strings.HasPrefix(markdown[loc[1]:], "/*") {
+			continue // escaped by the shortcode pass
+		}
+		report("escaped shortcode delimiter %s", markdown[loc[0]:loc[1]])
+		b.WriteString(markdown[last:loc[0]])
+		b.WriteString("{\u200b{" + markdown[loc[0]+2:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(markdown[last:])
+	return b.String()
+}
+
+// edit replaces source[start:stop].
+type edit struct {
+	start, stop int
+	text        string
+}
+
+// span is a range of the source.
+type span struct {
+	start, stop int
+}
+
+// sanitizeMarkdown parses markdown once, sanitizes the raw HTML blocks and
+// inline HTML the parser found, and removes unsafe link destinations from
+// the text outside code and HTML.
+func sanitizeMarkdown(markdown string, report func(string, ...interface{})) string {
+	source := []byte(markdown)
+	doc := markdownParser.Parse(text.NewReader(source))
+
+	var edits []edit
+	var skipped []span // Code and HTML, which have no link destinations
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.HTMLBlock:
+			segments := lineSegments(n.Lines())
+			if n.HasClosure() {
+				segments = append(segments, n.ClosureLine)
+			}
+			edits = append(edits, sanitizeHTML(source, segments, report)...)
+			skipped = append(skipped, segmentsSpan(segments))
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			segments := lineSegments(n.Segments)
+			edits = append(edits, sanitizeHTML(source, segments, report)...)
+			skipped = append(skipped, segmentsSpan(segments))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			skipped = append(skipped, segmentsSpan(lineSegments(n.Lines())))
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			var segments []text.Segment
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if t, ok := c.(*ast.Text); ok {
+					segments = append(segments, t.Segment)
+				}
+			}
+			skipped = append(skipped, segmentsSpan(segments))
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	edits = append(edits, sanitizeDestinations(markdown, skipped, report)...)
+	return applyEdits(markdown, edits)
+}
+
+func lineSegments(lines *text.Segments) []text.Segment {
+	segments := make([]text.Segment, lines.Len())
+	for i := range segments {
+		segments[i] = lines.At(i)
+	}
+	return segments
+}
+
+// segmentsSpan returns the source range covered by segments.
+func segmentsSpan(segments []text.Segment) span {
+	if len(segments) == 0 {
+		return span{}
+	}
+	return span{segments[0].Start, segments[len(segments)-1].Stop}
+}
+
+// sanitizeHTML tokenizes raw HTML the way browsers do and returns the edits
+// of the source that remove disallowed elements, tags and attributes. The
+// HTML is the text of segments, which leaves out the blockquote and list
+// prefixes between its lines, as the rendered page does.
+func sanitizeHTML(source []byte, segments []text.Segment, report func(string, ...interface{})) []edit {
+	var fragment []byte
+	var offsets []int // Source offset of each byte of fragment
+	for _, segment := range segments {
+		for i := segment.Start; i < segment.Stop; i++ {
+			fragment = append(fragment, source[i])
+			offsets = append(offsets, i)
+		}
+	}
+
+	var edits []edit
+	replace := func(start, stop int, text string) {
+		edits = append(edits, edit{offsets[start], offsets[stop-1] + 1, text})
+	}
+
+	z := html.NewTokenizer(strings.NewReader(string(fragment)))
+	pos := 0
+	skipping, depth := "", 0 // Removed element being skipped
+	for {
+		tokenType := z.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+		start, stop := pos, pos+len(raw)
+		pos = stop
+		token := z.Token()
+
+		if skipping != "" {
+			switch {
+			case tokenType == html.StartTagToken && token.Data == skipping:
+				depth++
+			case tokenType == html.EndTagToken && token.Data == skipping:
+				depth--
+				if depth == 0 {
+					skipping = ""
+				}
+			}
+			replace(start, stop, "")
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			// Kept as it is
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if removedElements[token.Data] {
+				report("removed <%s> element", token.Data)
+				if tokenType == html.StartTagToken {
+					skipping, depth = token.Data, 1
+				}
+				replace(start, stop, "")
+				continue
+			}
+			if !allowedTags[token.Data] {
+				report("removed <%s> tag", token.Data)
+				replace(start, stop, "")
+				continue
+			}
+			if attrs, changed := sanitizeAttrs(token.Data, token.Attr, report); changed {
+				token.Attr = attrs
+				replace(start, stop, token.String())
+			}
+		case html.EndTagToken:
+			if !allowedTags[token.Data] {
+				replace(start, stop, "")
+			} else if !strings.EqualFold(raw, "</"+token.Data+">") {
+				replace(start, stop, "</"+token.Data+">")
+			}
+		default:
+			// Comments, doctypes and processing instructions
+			replace(start, stop, "")
+		}
+	}
+
+	// A tag or comment cut off by the end of the HTML
+	if pos < len(fragment) && z.Err() == io.EOF {
+		replace(pos, len(fragment), "")
+	}
+	return edits
+}
+
+// sanitizeAttrs drops attributes outside the allowlist and URLs with unsafe
+// schemes. It reports whether any attribute was dropped.
+func sanitizeAttrs(tag string, attrs []html.Attribute, report func(string, ...interface{})) ([]html.Attribute, bool) {
+	var kept []html.Attribute
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !allowedAttrs[attr.Key] {
+			report("removed %s attribute from <%s>", attr.Key, tag)
+			continue
+		}
+		if attr.Key == "href" || attr.Key == "src" || attr.Key == "srcset" {
+			if unsafeURL(attr.Val) || attr.Key == "srcset" && unsafeSrcset(attr.Val) {
+				report("removed unsafe %s from <%s>", attr.Key, tag)
+				continue
+			}
+		}
+		kept = append(kept, attr)
+	}
+	return kept, len(kept) != len(attrs)
+}
+
+func unsafeSrcset(srcset string) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 && unsafeURL(fields[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// unsafeURL reports whether a URL has an unsafe scheme once character
+// references are decoded and the whitespace and control characters
+// browsers ignore are removed, e.g. "jav&#97;script:" or "java\tscript:".
+func unsafeURL(url string) bool {
+	url = html.UnescapeString(url)
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+	url = strings.ToLower(url)
+	for _, scheme := range unsafeSchemes {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeDestinations replaces unsafe destinations of inline links and
+// images, reference definitions and autolinks with "#". It looks at every
+// "](", "]:" and "<" outside the skipped ranges, so text that only looks
+// like a link may be changed too.
+func sanitizeDestinations(markdown string, skipped []span, report func(string, ...interface{})) []edit {
+	var edits []edit
+	for _, loc := range destinationPattern.FindAllStringIndex(markdown, -1) {
+		if inSpans(loc[0], skipped) {
+			continue
+		}
+		autolink := markdown[loc[0]] == '<'
+		start := loc[1]
+		if !autolink {
+			start = skipDestinationSpace(markdown, start)
+		}
+		start, stop := destinationRange(markdown, start, autolink)
+		destination := markdown[start:stop]
+		if !autolink {
+			destination = unescapeBackslashes(destination)
+		}
+		if unsafeURL(destination) {
+			report("removed unsafe link destination %s", markdown[start:stop])
+			edits = append(edits, edit{start, stop, "#"})
+		}
+	}
+	return edits
+}
+
+// skipDestinationSpace skips the spaces and at most one line break allowed
+// before a destination. The next line keeps its blockquote markers.
+func skipDestinationSpace(markdown string, i int) int {
+	for i < len(markdown) && (markdown[i] == ' ' || markdown[i] == '\t') {
+		i++
+	}
+	if strings.HasPrefix(markdown[i:], "\r\n") {
+		i += 2
+	} else if strings.HasPrefix(markdown[i:], "\n") {
+		i++
+	} else {
+		return i
+	}
+	for i < len(markdown) && strings.IndexByte(" \t>", markdown[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// destinationRange returns the destination starting at i: the text within
+// <>, or up to whitespace or an unbalanced ")". Autolinks end at ">".
+func destinationRange(markdown string, i int, autolink bool) (int, int) {
+	if !autolink && i < len(markdown) && markdown[i] == '<' {
+		i++
+		autolink = true
+	}
+	start, depth := i, 0
+	for ; i < len(markdown); i++ {
+		c := markdown[i]
+		switch {
+		case c <= ' ':
+			return start, i
+		case autolink:
+			if c == '>' || c == '<' {
+				return start, i
+			}
+		case c == '\\' && i+1 < len(markdown):
+			i++
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return start, i
+			}
+			depth--
+		}
+	}
+	return start, i
+}
+
+// unescapeBackslashes removes the backslashes escaping ASCII punctuation.
+func unescapeBackslashes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func inSpans(i int, spans []span) bool {
+	for _, s := range spans {
+		if i >= s.start && i < s.stop {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEdits applies non-overlapping edits to s.
+func applyEdits(s string, edits []edit) string {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			continue // Overlaps an earlier edit
+		}
+		b.WriteString(s[last:e.start])
+		b.WriteString(e.text)
+		last = e.stop
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package readme
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// alertTypes maps GitHub alert markers to their titles.
+var alertTypes = map[string]string{
+	"NOTE":      "Note",
+	"TIP":       "Tip",
+	"IMPORTANT": "Important",
+	"WARNING":   "Warning",
+	"CAUTION":   "Caution",
+}
+
+var (
+	alertPattern       = regexp.MustCompile(`^\s{0,3}>\s*\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\]\s*$`)
+	atxH1Pattern       = regexp.MustCompile(`^#\s+(.+?)\s*#*\s*$`)
+	htmlH1Pattern      = regexp.MustCompile(`(?is)^<h1\b[^>]*>(.*?)</h1>\s*$`)
+	htmlHeadingPattern = regexp.MustCompile(`(?i)<h([1-6])((?:\s[^>]*)?)>(.*?)</h[1-6]>`)
+	idAttrPattern      = regexp.MustCompile(`(?i)\sid\s*=`)
+	userContentPattern = regexp.MustCompile(`\(#user-content-`)
+	userContentHref    = regexp.MustCompile(`href=(["'])#user-content-`)
+	markupPattern      = regexp.MustCompile(`<[^>]*>|!?\[([^\]]*)\]\([^)]*\)|[*_` + "`" + `]`)
+	slugStripPattern   = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+	whitespacePattern  = regexp.MustCompile(`\s`)
+)
+
+// Transform converts GitHub-specific README constructs into Markdown and
+// HTML the site renders: alert blocks become styled notes, mermaid fences
+// become diagrams, "user-content-" anchors and HTML headings get the ids
+// the site generates, and a leading H1 repeating one of the given titles is
+// dropped since the package page already shows it.
+func Transform(markdown string, titles ...string) string {
+	markdown = stripLeadingTitle(markdown, titles)
+	markdown = convertBlocks(markdown)
+
+	return mapProse(markdown, func(text string) string {
+		text = userContentPattern.ReplaceAllString(text, "(#")
+		text = userContentHref.ReplaceAllString(text, "href=$1#")
+		return htmlHeadingPattern.ReplaceAllStringFunc(text, func(match string) string {
+			m := htmlHeadingPattern.FindStringSubmatch(match)
+			if idAttrPattern.MatchString(m[2]) {
+				return match
+			}
+			return "<h" + m[1] + ` id="` + slugify(m[3]) + `"` + m[2] + ">" + m[3] + "</h" + m[1] + ">"
+		})
+	})
+}
+
+// stripLeadingTitle removes the first heading when it is a level 1 heading
+// whose text matches one of titles.
+func stripLeadingTitle(markdown string, titles []string) string {
+	lines := strings.Split(markdown, "\n")
+	first := 0
+	for first < len(lines) && strings.TrimSpace(lines[first]) == "" {
+		first++
+	}
+	if first == len(lines) {
+		return markdown
+	}
+
+	var text string
+	end := first + 1
+	if m := atxH1Pattern.FindStringSubmatch(lines[first]); m != nil {
+		text = m[1]
+	} else if m := htmlH1Pattern.FindStringSubmatch(lines[first]); m != nil {
+		text = m[1]
+	} else if first+1 < len(lines) && strings.Trim(lines[first+1], "= ") == "" && strings.Contains(lines[first+1], "=") {
+		text = lines[first] // setext heading
+		end = first + 2
+	} else {
+		return markdown
+	}
+
+	plain := strings.TrimSpace(plainText(text))
+	for _, title := range titles {
+		if title != "" && strings.EqualFold(plain, title) {
+			rest := lines[end:]
+			for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+				rest = rest[1:]
+			}
+			return strings.Join(append(lines[:first:first], rest...), "\n")
+		}
+	}
+	return markdown
+}
+
+// convertBlocks rewrites alert blockquotes and mermaid fences line by line.
+func convertBlocks(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	fence := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			out = append(out, line)
+			continue
+		}
+
+		if opening, ok := openingFence(line); ok {
+			info := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), opening[:1]))
+			if info != "mermaid" {
+				fence = opening
+				out = append(out, line)
+				continue
+			}
+
+			var diagram []string
+			j := i + 1
+			for ; j < len(lines) && !closesFence(lines[j], opening); j++ {
+				diagram = append(diagram, lines[j])
+			}
+			out = append(out, `<pre class="mermaid">`+html.EscapeString(strings.Join(diagram, "\n"))+`</pre>`)
+			i = j
+			continue
+		}
+
+		if m := alertPattern.FindStringSubmatch(line); m != nil {
+			var body []string
+			j := i + 1
+			for ; j < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[j]), ">"); j++ {
+				content := strings.TrimPrefix(strings.TrimSpace(lines[j]), ">")
+				body = append(body, strings.TrimPrefix(content, " "))
+			}
+			kind := strings.ToLower(m[1])
+			out = append(out,
+				`<div class="markdown-alert markdown-alert-`+kind+`">`,
+				`<p class="markdown-alert-title">`+alertTypes[m[1]]+`</p>`,
+				"",
+			)
+			out = append(out, body...)
+			out = append(out, "", "</div>")
+			i = j - 1
+			continue
+		}
+
+		out = append(out, line)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// slugify builds a heading id the way GitHub and Hugo's "github" heading
+// ids do.
+func slugify(text string) string {
+	slug := strings.ToLower(strings.TrimSpace(html.UnescapeString(plainText(text))))
+	slug = slugStripPattern.ReplaceAllString(slug, "")
+	return whitespacePattern.ReplaceAllString(slug, "-")
+}
+
+// plainText strips inline HTML and Markdown markup, keeping link text.
+func plainText(text string) string {
+	return markupPattern.ReplaceAllString(text, "$1")
+}
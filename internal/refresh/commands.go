@@ -0,0 +1,192 @@
+package refresh
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"go.ngs.io/internal/source"
+)
+
+// Commands detects the installable commands of a module at ref: the module
+// root and each cmd/ subdirectory whose sources declare package main. The
+// result holds import paths rooted at importPath.
+func Commands(src source.Source, repoURL, ref, importPath string) ([]string, error) {
+	commands := []string{}
+
+	entries, err := src.ListDir(repoURL, "", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	isMain, err := isMainPackage(src, repoURL, ref, entries)
+	if err != nil {
+		return nil, err
+	}
+	if isMain {
+		commands = append(commands, importPath)
+	}
+
+	hasCmdDir := false
+	for _, entry := range entries {
+		if entry.Dir && entry.Name == "cmd" {
+			hasCmdDir = true
+			break
+		}
+	}
+	if !hasCmdDir {
+		return commands, nil
+	}
+
+	cmdEntries, err := src.ListDir(repoURL, "cmd", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range cmdEntries {
+		if !dir.Dir {
+			continue
+		}
+
+		files, err := src.ListDir(repoURL, dir.Path, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		isMain, err := isMainPackage(src, repoURL, ref, files)
+		if err != nil {
+			return nil, err
+		}
+		if isMain {
+			commands = append(commands, path.Join(importPath, dir.Path))
+		}
+	}
+
+	return commands, nil
+}
+
+// isMainPackage reports whether the Go files of a directory listing declare
+// package main. Test files and files excluded from every build by their
+// build constraints are not considered; the other files must all declare
+// package main, as the go command rejects a directory mixing packages.
+func isMainPackage(src source.Source, repoURL, ref string, entries []source.DirEntry) (bool, error) {
+	isMain := false
+	for _, entry := range entries {
+		if entry.Dir || !strings.HasSuffix(entry.Name, ".go") || strings.HasSuffix(entry.Name, "_test.go") {
+			continue
+		}
+
+		src, err := src.File(repoURL, entry.Path, ref)
+		if err != nil {
+			return false, err
+		}
+
+		name, ok := packageName(entry.Name, src)
+		if !ok {
+			continue
+		}
+		if name != "main" {
+			return false, nil
+		}
+		isMain = true
+	}
+
+	return isMain, nil
+}
+
+// Operating systems and architectures build constraints are evaluated for,
+// as listed by go tool dist list.
+var (
+	knownOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js",
+		"linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows",
+	}
+	knownArch = []string{
+		"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le",
+		"mipsle", "ppc64", "ppc64le", "riscv64", "s390x", "wasm",
+	}
+)
+
+// unixOS lists the operating systems matched by the unix build tag.
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true, "illumos": true,
+	"ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+// packageName returns the package clause of a Go source file. Files that do
+// not parse or are excluded from every build are skipped.
+func packageName(filename string, src []byte) (string, bool) {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return "", false
+	}
+
+	expr, err := buildConstraint(f)
+	if err != nil {
+		return "", false
+	}
+	if expr != nil && !anyTarget(expr) {
+		return "", false
+	}
+
+	return f.Name.Name, true
+}
+
+// buildConstraint returns the build constraint of a file: its //go:build
+// line, or else its // +build lines combined. Only comments before the
+// package clause count. A file without constraints yields nil.
+func buildConstraint(f *ast.File) (constraint.Expr, error) {
+	var plusBuild constraint.Expr
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, comment := range group.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				return constraint.Parse(comment.Text)
+			case constraint.IsPlusBuild(comment.Text):
+				expr, err := constraint.Parse(comment.Text)
+				if err != nil {
+					return nil, err
+				}
+				if plusBuild == nil {
+					plusBuild = expr
+				} else {
+					plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+				}
+			}
+		}
+	}
+	return plusBuild, nil
+}
+
+// anyTarget reports whether a build constraint is satisfied on any known
+// platform, with or without cgo, by the gc compiler of any Go release.
+func anyTarget(expr constraint.Expr) bool {
+	for _, goos := range knownOS {
+		for _, goarch := range knownArch {
+			for _, cgo := range []bool{true, false} {
+				satisfied := expr.Eval(func(tag string) bool {
+					switch {
+					case tag == goos, tag == goarch, tag == "gc":
+						return true
+					case tag == "cgo":
+						return cgo
+					case tag == "unix":
+						return unixOS[goos]
+					default:
+						return strings.HasPrefix(tag, "go1.")
+					}
+				})
+				if satisfied {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
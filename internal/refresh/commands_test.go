@@ -0,0 +1,60 @@
+package refresh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	src := newFakeSource(map[string]string{
+		"go.mod":  "module go.ngs.io/tool\n",
+		"main.go": "package main\n",
+		// A code generator excluded from the build of the root package
+		"gen.go":       "//go:build ignore\n\npackage generate\n",
+		"main_test.go": "package main_test\n",
+
+		// Commands built on some platforms only
+		"cmd/tool/main.go":          "package main\n",
+		"cmd/winonly/main.go":       "//go:build windows && !cgo\n\npackage main\n",
+		"cmd/legacy/main.go":        "// +build linux darwin\n\npackage main\n",
+		"cmd/impossible/main.go":    "//go:build linux && windows\n\npackage main\n",
+		"cmd/notbuild/main.go":      "// Not a //go:build ignore line\npackage main\n",
+		"cmd/tools/tools.go":        "//go:build tools\n\npackage tools\n",
+		"cmd/tools/main.go":         "package main\n",
+		"cmd/lib/lib.go":            "package lib\n",
+		"cmd/mixed/a.go":            "package lib\n",
+		"cmd/mixed/main.go":         "package main\n",
+		"cmd/broken/main.go":        "packag main\n",
+		"cmd/broken/util.go":        "package main\n",
+		"cmd/ignoreword/main.go":    "//go:build !ignorecase\n\npackage main\n",
+		"cmd/onlytests/x_test.go":   "package main\n",
+		"cmd/docs/README.md":        "# Docs\n",
+		"cmd/afterclause/main.go":   "package main\n\n//go:build ignore\n",
+		"cmd/unix/main.go":          "//go:build unix\n\npackage main\n",
+		"cmd/plan9only/main.go":     "//go:build plan9\n\npackage main\n",
+		"cmd/badconstraint/x.go":    "//go:build linux &&\n\npackage main\n",
+		"cmd/badconstraint/main.go": "package main\n",
+	})
+
+	commands, err := Commands(src, "", "", "go.ngs.io/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"go.ngs.io/tool",
+		"go.ngs.io/tool/cmd/afterclause",
+		"go.ngs.io/tool/cmd/badconstraint",
+		"go.ngs.io/tool/cmd/broken",
+		"go.ngs.io/tool/cmd/ignoreword",
+		"go.ngs.io/tool/cmd/legacy",
+		"go.ngs.io/tool/cmd/notbuild",
+		"go.ngs.io/tool/cmd/plan9only",
+		"go.ngs.io/tool/cmd/tool",
+		"go.ngs.io/tool/cmd/tools",
+		"go.ngs.io/tool/cmd/unix",
+		"go.ngs.io/tool/cmd/winonly",
+	}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("Commands =\n%v\nwant\n%v", commands, want)
+	}
+}
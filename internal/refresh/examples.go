@@ -0,0 +1,168 @@
+package refresh
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"go.ngs.io/internal/godoc"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/source"
+)
+
+// Examples collects the Example functions of every package of a module at
+// ref. Only packages with test files are read.
+func Examples(src source.Source, repoURL, ref, importPath string) ([]hugo.Example, error) {
+	module, err := godoc.LoadExamples(src, repoURL, ref, importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	examples := []hugo.Example{}
+	for _, ex := range module.Examples() {
+		examples = append(examples, hugo.Example(ex))
+	}
+	return examples, nil
+}
+
+// Usage reads the usage text of a command from a file of the repository at
+// ref. Go files yield the text printed by their usage function or the value
+// of a usage string constant; other files are used verbatim.
+func Usage(src source.Source, repoURL, ref, usageFile string) (string, error) {
+	content, err := src.File(repoURL, usageFile, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if path.Ext(usageFile) != ".go" {
+		return strings.TrimSpace(string(content)), nil
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), usageFile, content, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", usageFile, err)
+	}
+
+	usage := usageText(f)
+	if usage == "" {
+		return "", fmt.Errorf("no usage text found in %s", usageFile)
+	}
+	return usage, nil
+}
+
+// usageText extracts the usage text of a Go file: the strings printed by a
+// function whose name contains "usage" or a function literal assigned to
+// flag.Usage, or else a string constant or variable named like usage.
+func usageText(f *ast.File) string {
+	var printed strings.Builder
+	var constant string
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil && isUsageName(n.Name.Name) {
+				printed.WriteString(printedText(n.Body))
+				return false
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "Usage" && i < len(n.Rhs) {
+					if lit, ok := n.Rhs[i].(*ast.FuncLit); ok {
+						printed.WriteString(printedText(lit.Body))
+						return false
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if constant == "" && isUsageName(name.Name) && i < len(n.Values) {
+					constant, _ = stringValue(n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+
+	if text := strings.TrimSpace(printed.String()); text != "" {
+		return text
+	}
+	return strings.TrimSpace(constant)
+}
+
+func isUsageName(name string) bool {
+	return strings.Contains(strings.ToLower(name), "usage")
+}
+
+// printedText concatenates the string literals printed with fmt.Print*
+// and fmt.Fprint* in body. Format verbs of Printf calls are kept as is.
+func printedText(body *ast.BlockStmt) string {
+	var b strings.Builder
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "fmt" {
+			return true
+		}
+
+		args := call.Args
+		name := sel.Sel.Name
+		if strings.HasPrefix(name, "Fprint") && len(args) > 0 {
+			args = args[1:] // Writer
+			name = "Print" + strings.TrimPrefix(name, "Fprint")
+		}
+
+		var parts []string
+		for _, arg := range args {
+			if s, ok := stringValue(arg); ok {
+				parts = append(parts, s)
+			}
+			if name == "Printf" {
+				break // Only the format
+			}
+		}
+
+		switch name {
+		case "Println":
+			b.WriteString(strings.Join(parts, " ") + "\n")
+		case "Print", "Printf":
+			b.WriteString(strings.Join(parts, ""))
+		}
+		return false
+	})
+	return b.String()
+}
+
+// stringValue evaluates string literals and their concatenations.
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
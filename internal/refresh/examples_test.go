@@ -0,0 +1,40 @@
+package refresh
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.ngs.io/internal/hugo"
+)
+
+func TestExamples(t *testing.T) {
+	src := newFakeSource(map[string]string{
+		"go.mod":    "module go.ngs.io/widget\n",
+		"widget.go": "package widget\n\n// New returns a widget.\nfunc New() int { return 1 }\n",
+		"example_test.go": "package widget_test\n\nimport (\n\t\"fmt\"\n\n\t\"go.ngs.io/widget\"\n)\n\n" +
+			"func ExampleNew() {\n\tfmt.Println(widget.New())\n\t// Output: 1\n}\n",
+		"render/render.go":   "package render\n\nfunc Render() {}\n",
+		"render/util.go":     "package render\n",
+		"cmd/widget/main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	examples, err := Examples(src, "https://github.com/ngs/widget", "v1.0.0", "go.ngs.io/widget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []hugo.Example{{Name: "New", Code: "fmt.Println(widget.New())", Output: "1\n"}}
+	if !reflect.DeepEqual(examples, want) {
+		t.Errorf("Examples = %+v, want %+v", examples, want)
+	}
+
+	// Packages without test files are not fetched
+	var read []string
+	for file := range src.reads {
+		read = append(read, file)
+	}
+	sort.Strings(read)
+	if want := []string{"example_test.go", "widget.go"}; !reflect.DeepEqual(read, want) {
+		t.Errorf("read %v, want only %v", read, want)
+	}
+}
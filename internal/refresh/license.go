@@ -0,0 +1,45 @@
+package refresh
+
+import (
+	"fmt"
+
+	"go.ngs.io/internal/license"
+	"go.ngs.io/internal/source"
+)
+
+// License classifies the license files in the repository root at ref. The
+// note is empty for verbatim matches and describes the confidence of
+// heuristic ones. An empty id means no license could be identified.
+func License(src source.Source, repoURL, ref string) (id, note string, err error) {
+	entries, err := src.ListDir(repoURL, "", ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	var best license.Match
+	var bestFile string
+	for _, entry := range entries {
+		if entry.Dir || !license.IsLicenseFile(entry.Name) {
+			continue
+		}
+
+		text, err := src.File(repoURL, entry.Path, ref)
+		if err != nil {
+			return "", "", err
+		}
+
+		if match, ok := license.Classify(text); ok && match.Confidence > best.Confidence {
+			best = match
+			bestFile = entry.Name
+		}
+	}
+
+	if best.ID == "" {
+		return "", "", nil
+	}
+	if best.Heuristic() {
+		note = fmt.Sprintf("detected from %s with %.0f%% confidence", bestFile, best.Confidence*100)
+	}
+
+	return best.ID, note, nil
+}
@@ -0,0 +1,59 @@
+package refresh
+
+import (
+	"strings"
+	"testing"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Atsushi Nagase
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+func TestLicense(t *testing.T) {
+	edited := strings.Replace(mitText, "THE SOFTWARE IS PROVIDED",
+		"This software is distributed with the following disclaimer. THE SOFTWARE IS PROVIDED", 1) +
+		"\nAdditional terms: the authors must be credited in the documentation of any product using the Software, " +
+		"and the name of the authors may not be used to endorse derived products.\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		id    string
+		note  string
+	}{
+		{"verbatim", map[string]string{"LICENSE": mitText, "main.go": "package main\n"}, "MIT", ""},
+		{"edited", map[string]string{"LICENSE.md": edited}, "MIT", "detected from LICENSE.md with 89% confidence"},
+		{"not a license", map[string]string{"COPYING": "Do not copy.\n"}, "", ""},
+		{"no license file", map[string]string{"README.md": mitText}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, note, err := License(newFakeSource(tt.files), "", "main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.id || note != tt.note {
+				t.Errorf("License = %q, %q; want %q, %q", id, note, tt.id, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package refresh
+
+import (
+	"go.ngs.io/internal/depgraph"
+	"go.ngs.io/internal/gomod"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/source"
+	"golang.org/x/mod/module"
+)
+
+// ModFile fetches and parses go.mod at the latest version of the module at
+// importPath, which is where the go command reads retractions and
+// deprecation notices from. Without versions the default branch is used.
+func ModFile(src source.Source, repoURL, importPath string, versions []hugo.Version) (*gomod.File, error) {
+	data, err := src.File(repoURL, "go.mod", latestTag(versions, importPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return gomod.Parse(data)
+}
+
+// MarkRetracted flags the versions retracted by the given go.mod.
+func MarkRetracted(versions []hugo.Version, modFile *gomod.File) {
+	for i := range versions {
+		versions[i].Retracted = modFile.Retracted(versions[i].Version)
+	}
+}
+
+// LatestVersion selects the version to advertise: the highest release that
+// is not retracted, falling back to the highest non-retracted pre-release.
+// Only versions of the major version of importPath are considered, so a v1
+// module ignores the v2 tags of its repository.
+func LatestVersion(versions []hugo.Version, importPath string) string {
+	var prerelease string
+	for _, v := range ModuleVersions(versions, importPath) {
+		if v.Retracted {
+			continue
+		}
+		if v.Prerelease {
+			if prerelease == "" {
+				prerelease = v.Version
+			}
+			continue
+		}
+		return v.Version
+	}
+	return prerelease
+}
+
+// latestTag returns the tag the go command would treat as latest when
+// looking up retractions, ignoring whether it is itself retracted.
+func latestTag(versions []hugo.Version, importPath string) string {
+	versions = ModuleVersions(versions, importPath)
+	for _, v := range versions {
+		if !v.Prerelease {
+			return v.Version
+		}
+	}
+	if len(versions) > 0 {
+		return versions[0].Version
+	}
+	return ""
+}
+
+// ModuleVersions returns the versions whose major version matches the /vN
+// suffix of importPath.
+func ModuleVersions(versions []hugo.Version, importPath string) []hugo.Version {
+	_, pathMajor, _ := module.SplitPathVersion(importPath)
+	var matching []hugo.Version
+	for _, v := range versions {
+		if module.CheckPathMajor(v.Version, pathMajor) == nil {
+			matching = append(matching, v)
+		}
+	}
+	return matching
+}
+
+// Requirements returns the direct requirements of a go.mod on modules of
+// the registry, given by their import paths. Requirements on the module
+// itself, e.g. from nested modules, are skipped.
+func Requirements(modFile *gomod.File, importPath string, registry []string) []hugo.Requirement {
+	requires := []hugo.Requirement{}
+	for _, r := range modFile.Require {
+		if r.Indirect {
+			continue
+		}
+		if owner := depgraph.Owner(r.Path, registry); owner != "" && owner != importPath {
+			requires = append(requires, hugo.Requirement{Path: r.Path, Version: r.Version})
+		}
+	}
+	return requires
+}
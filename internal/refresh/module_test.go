@@ -0,0 +1,39 @@
+package refresh
+
+import (
+	"testing"
+
+	"go.ngs.io/internal/hugo"
+)
+
+func TestLatestVersion(t *testing.T) {
+	// Newest first, as returned by Versions
+	versions := []hugo.Version{
+		{Version: "v3.0.0-beta.1", Prerelease: true},
+		{Version: "v2.1.0", Retracted: true},
+		{Version: "v2.0.0"},
+		{Version: "v1.2.0-rc.1", Prerelease: true},
+		{Version: "v1.1.0", Retracted: true},
+		{Version: "v1.0.0"},
+		{Version: "v0.9.0"},
+	}
+	tests := []struct {
+		importPath string
+		latest     string
+		tag        string
+	}{
+		{"go.ngs.io/tool", "v1.0.0", "v1.1.0"},
+		{"go.ngs.io/tool/v2", "v2.0.0", "v2.1.0"},
+		{"go.ngs.io/tool/v3", "v3.0.0-beta.1", "v3.0.0-beta.1"},
+		{"go.ngs.io/tool/v4", "", ""},
+		{"gopkg.in/tool.v1", "v1.0.0", "v1.1.0"},
+	}
+	for _, tt := range tests {
+		if got := LatestVersion(versions, tt.importPath); got != tt.latest {
+			t.Errorf("LatestVersion for %s = %q, want %q", tt.importPath, got, tt.latest)
+		}
+		if got := latestTag(versions, tt.importPath); got != tt.tag {
+			t.Errorf("latestTag for %s = %q, want %q", tt.importPath, got, tt.tag)
+		}
+	}
+}
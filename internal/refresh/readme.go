@@ -0,0 +1,122 @@
+package refresh
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.ngs.io/internal/github"
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/readme"
+	"go.ngs.io/internal/source"
+)
+
+// Readme fetches the README, rewrites relative links and images to point at
+// the repository at ref, normally the default branch the README was read
+// from, sanitizes it for embedding in the site and converts GitHub-specific
+// Markdown. A leading H1 repeating one of titles or the repository name is
+// dropped. The returned changes describe what the sanitizer altered.
+func Readme(src source.Source, repoURL, ref string, titles ...string) (string, []string, error) {
+	body, err := src.Readme(repoURL)
+	if err != nil || body == "" {
+		return body, nil, err
+	}
+
+	body, changes := processReadme(body, repoURL, ref, titles)
+	return body, changes, nil
+}
+
+// LocalizedReadmes fetches the README.<lang>.md variants in the repository
+// root for the given site languages and processes them like Readme. The
+// result is keyed by language.
+func LocalizedReadmes(src source.Source, repoURL, ref string, languages []string, titles ...string) (map[string]string, []string, error) {
+	entries, err := src.ListDir(repoURL, "", ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, lang := range languages {
+		wanted[lang] = true
+	}
+
+	readmes := map[string]string{}
+	var changes []string
+	for _, entry := range entries {
+		lang := readme.Language(entry.Name)
+		if entry.Dir || !wanted[lang] {
+			continue
+		}
+		if _, ok := readmes[lang]; ok {
+			continue // README.ja.md and README_ja.md; keep the first
+		}
+
+		content, err := src.File(repoURL, entry.Path, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		body, sanitized := processReadme(string(content), repoURL, ref, titles)
+		readmes[lang] = body
+		for _, change := range sanitized {
+			changes = append(changes, entry.Name+": "+change)
+		}
+	}
+
+	return readmes, changes, nil
+}
+
+func processReadme(body, repoURL, ref string, titles []string) (string, []string) {
+	body = readme.RewriteLinks(body, readmeLinks(repoURL, ref))
+	body, changes := readme.Sanitize(body)
+
+	repoName := strings.TrimSuffix(path.Base(strings.TrimSuffix(repoURL, "/")), ".git")
+	body = readme.Transform(body, append(titles, repoName)...)
+
+	return body, changes
+}
+
+// readmeLinks returns the blob and raw URL prefixes of a GitHub repository.
+// Other repositories have no known layout and keep their relative links.
+func readmeLinks(repoURL, ref string) readme.Links {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return readme.Links{}
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	return readme.Links{
+		Blob: fmt.Sprintf("https://github.com/%s/%s/blob/%s", owner, repo, ref),
+		Raw:  fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", owner, repo, ref),
+	}
+}
+
+// Localize applies localized READMEs to the localizations of a package and
+// returns the languages that changed. A localization whose README is gone is
+// removed unless it carries its own description.
+func Localize(pkg *hugo.Package, readmes map[string]string) []string {
+	var changed []string
+	for _, lang := range hugo.Languages {
+		body, ok := readmes[lang]
+		localization := pkg.Localizations[lang]
+		switch {
+		case ok && localization == nil:
+			if pkg.Localizations == nil {
+				pkg.Localizations = map[string]*hugo.Localization{}
+			}
+			pkg.Localizations[lang] = &hugo.Localization{Body: body}
+		case ok && localization.Body != body:
+			localization.Body = body
+		case !ok && localization != nil && localization.Description == "":
+			delete(pkg.Localizations, lang)
+		case !ok && localization != nil && localization.Body != "":
+			localization.Body = ""
+		default:
+			continue
+		}
+		changed = append(changed, lang)
+	}
+	return changed
+}
@@ -0,0 +1,71 @@
+package refresh
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"go.ngs.io/internal/source"
+)
+
+// fakeSource serves the files of a repository from memory, keyed by their
+// slash-separated path, at any ref. It counts the files read.
+type fakeSource struct {
+	files map[string]string
+	reads map[string]int
+}
+
+func newFakeSource(files map[string]string) *fakeSource {
+	return &fakeSource{files: files, reads: map[string]int{}}
+}
+
+func (s *fakeSource) Name() string { return "fake" }
+
+func (s *fakeSource) Repository(repoURL string) (*source.Repository, error) {
+	return &source.Repository{DefaultBranch: "main"}, nil
+}
+
+func (s *fakeSource) Versions(repoURL string) ([]source.Version, error) { return nil, nil }
+
+func (s *fakeSource) LatestVersion(repoURL string) (string, error) { return "", nil }
+
+func (s *fakeSource) Readme(repoURL string) (string, error) { return s.files["README.md"], nil }
+
+func (s *fakeSource) ReleaseURL(repoURL, tag string) string { return "" }
+
+func (s *fakeSource) File(repoURL, filePath, ref string) ([]byte, error) {
+	content, ok := s.files[filePath]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", filePath, fs.ErrNotExist)
+	}
+	s.reads[filePath]++
+	return []byte(content), nil
+}
+
+func (s *fakeSource) ListDir(repoURL, dirPath, ref string) ([]source.DirEntry, error) {
+	prefix := ""
+	if dirPath != "" {
+		prefix = dirPath + "/"
+	}
+	seen := map[string]bool{}
+	var entries []source.DirEntry
+	for filePath := range s.files {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
+		}
+		name, _, dir := strings.Cut(rest, "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entries = append(entries, source.DirEntry{Name: name, Path: path.Join(dirPath, name), Dir: dir})
+	}
+	if len(entries) == 0 && dirPath != "" {
+		return nil, fmt.Errorf("%s: %w", dirPath, fs.ErrNotExist)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
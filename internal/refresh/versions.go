@@ -0,0 +1,43 @@
+// Package refresh derives hugo.Package metadata from the upstream
+// repository. It is shared by add-package and update-packages.
+package refresh
+
+import (
+	"go.ngs.io/internal/hugo"
+	"go.ngs.io/internal/source"
+)
+
+// Versions fetches the version history of a repository, newest first.
+func Versions(src source.Source, repoURL string) ([]hugo.Version, error) {
+	srcVersions, err := src.Versions(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]hugo.Version, 0, len(srcVersions))
+	for _, v := range srcVersions {
+		versions = append(versions, hugo.Version{
+			Version:    v.Name,
+			Date:       v.Date.UTC(),
+			Name:       v.ReleaseName,
+			Prerelease: v.Prerelease,
+		})
+	}
+
+	return versions, nil
+}
+
+// VersionsEqual reports whether two version histories are identical.
+func VersionsEqual(a, b []hugo.Version) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Version != b[i].Version || !a[i].Date.Equal(b[i].Date) ||
+			a[i].Name != b[i].Name || a[i].Prerelease != b[i].Prerelease ||
+			a[i].Retracted != b[i].Retracted {
+			return false
+		}
+	}
+	return true
+}
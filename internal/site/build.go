@@ -0,0 +1,278 @@
+package site
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+)
+
+// Builder names of NewBuilder.
+const (
+	BuilderHugo   = "hugo"
+	BuilderNative = "native"
+)
+
+// BuildError is a problem found by a build, located in a content or layout
+// file when the builder reports one.
+type BuildError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e BuildError) Error() string {
+	switch {
+	case e.File == "":
+		return e.Message
+	case e.Line == 0:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	case e.Column == 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+}
+
+// BuildErrors are the problems of a failed build, one per line.
+type BuildErrors []BuildError
+
+func (e BuildErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// BuildOptions configure a build.
+type BuildOptions struct {
+	// Temporary builds into a temporary directory that is removed
+	// afterwards, leaving the output directory of the domain untouched.
+	Temporary bool
+	// Args are passed to the builder, e.g. "--gc", "--minify" for Hugo.
+	Args []string
+	// Log receives the command line and output of the build; nil discards
+	// them.
+	Log io.Writer
+}
+
+// Builder builds the site of a domain. A failed build returns BuildErrors.
+type Builder interface {
+	Name() string
+	Build(cfg *config.Config, domain string, opts BuildOptions) error
+}
+
+// NewBuilder returns the builder with the given name: hugo runs Hugo, native
+// only parses the content files and needs no Hugo installation.
+func NewBuilder(name string) (Builder, error) {
+	switch name {
+	case BuilderHugo:
+		return Hugo{}, nil
+	case BuilderNative:
+		return Native{}, nil
+	default:
+		return nil, fmt.Errorf("unknown builder: %s (available: %s, %s)", name, BuilderHugo, BuilderNative)
+	}
+}
+
+// Hugo builds a domain with the hugo command.
+type Hugo struct{}
+
+func (Hugo) Name() string { return BuilderHugo }
+
+func (Hugo) Build(cfg *config.Config, domain string, opts BuildOptions) error {
+	log := opts.Log
+	if log == nil {
+		log = io.Discard
+	}
+
+	args := opts.Args
+	if opts.Temporary {
+		dir, err := os.MkdirTemp("", "site-*")
+		if err != nil {
+			return fmt.Errorf("failed to create build directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		args = append(append([]string{}, args...), "--destination", dir)
+	}
+
+	cmd, cleanup, err := Command(cfg, domain, args...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	fmt.Fprintf(log, "Running %s\n", strings.Join(cmd.Args, " "))
+
+	output, err := cmd.CombinedOutput()
+	log.Write(output)
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return fmt.Errorf("failed to run hugo: %w", err)
+		}
+		return parseHugoErrors(output)
+	}
+	return nil
+}
+
+// hugoLocation matches the quoted "file:line:column": prefix of Hugo
+// errors.
+var hugoLocation = regexp.MustCompile(`"([^"]+):(\d+):(\d+)": (.*)$`)
+
+// parseHugoErrors extracts the errors from the output of a failed Hugo
+// build. Hugo prints each error as an ERROR line and repeats the first in
+// the final "Error:" line, so duplicates are dropped. Without any
+// recognizable line the whole output is returned as one error.
+func parseHugoErrors(output []byte) BuildErrors {
+	var errs BuildErrors
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		message, ok := strings.CutPrefix(line, "ERROR ")
+		if !ok {
+			if message, ok = strings.CutPrefix(line, "Error: "); !ok {
+				continue
+			}
+		}
+
+		buildErr := BuildError{Message: message}
+		if m := hugoLocation.FindStringSubmatch(message); m != nil {
+			buildErr.File = relativePath(m[1])
+			buildErr.Line, _ = strconv.Atoi(m[2])
+			buildErr.Column, _ = strconv.Atoi(m[3])
+			buildErr.Message = m[4]
+		}
+		if key := buildErr.Error(); !seen[key] {
+			seen[key] = true
+			errs = append(errs, buildErr)
+		}
+	}
+
+	// Errors without a location, such as "error building site: render: ...",
+	// summarize the located ones; they are only kept if nothing is located.
+	var located, unlocated BuildErrors
+	for _, e := range errs {
+		if e.File != "" {
+			located = append(located, e)
+		} else {
+			unlocated = append(unlocated, e)
+		}
+	}
+	if len(located) > 0 {
+		errs = located
+	} else {
+		errs = unlocated
+	}
+
+	if len(errs) == 0 {
+		message := strings.TrimSpace(string(output))
+		if message == "" {
+			message = "hugo failed without output"
+		}
+		return BuildErrors{{Message: message}}
+	}
+	return errs
+}
+
+// relativePath returns path relative to the working directory when it is
+// inside it, as content files are named in the rest of the output.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// Native checks the content files of a domain without Hugo: every page must
+// have well-formed YAML frontmatter. Layouts are not checked, and nothing is
+// written, so Temporary and Args have no effect.
+type Native struct{}
+
+func (Native) Name() string { return BuilderNative }
+
+func (Native) Build(cfg *config.Config, domain string, opts BuildOptions) error {
+	d, err := cfg.Lookup(domain)
+	if err != nil {
+		return err
+	}
+	if opts.Log != nil {
+		fmt.Fprintf(opts.Log, "Checking content files in %s\n", d.ContentDir)
+	}
+
+	var files []string
+	err = filepath.WalkDir(d.ContentDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.HasSuffix(path, ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list content files: %w", err)
+	}
+	sort.Strings(files)
+
+	var errs BuildErrors
+	for _, file := range files {
+		if buildErr := checkFrontmatter(file); buildErr != nil {
+			errs = append(errs, *buildErr)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkFrontmatter parses the frontmatter of a content file, returning the
+// problem at its line in the file, or nil.
+func checkFrontmatter(file string) *BuildError {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return &BuildError{File: file, Message: err.Error()}
+	}
+
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	if !ok {
+		return nil // No frontmatter
+	}
+	frontmatter, _, ok := bytes.Cut(rest, []byte("\n---"))
+	if !ok {
+		return &BuildError{File: file, Line: 1, Message: "frontmatter is not closed with ---"}
+	}
+
+	var values map[string]interface{}
+	if err := hugo.ParseFrontmatter(file, frontmatter, &values); err != nil {
+		buildErr := PageError(err)
+		return &buildErr
+	}
+	return nil
+}
+
+// PageError returns the build error of a page that cannot be read, located
+// at the line of a *hugo.FrontmatterError.
+func PageError(err error) BuildError {
+	var pageErr *hugo.FrontmatterError
+	if errors.As(err, &pageErr) {
+		return BuildError{File: pageErr.File, Line: pageErr.Line, Message: pageErr.Message}
+	}
+	return BuildError{Message: err.Error()}
+}
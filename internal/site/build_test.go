@@ -0,0 +1,135 @@
+package site
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.ngs.io/internal/config"
+	"go.ngs.io/internal/hugo"
+)
+
+func TestParseHugoErrors(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := filepath.Join(wd, "layouts", "_default", "single.html")
+
+	// Output of hugo v0.139.0
+	tests := []struct {
+		name   string
+		output string
+		want   BuildErrors
+	}{
+		{
+			name: "template error repeated in the summary",
+			output: "Start building sites … \n" +
+				"hugo v0.139.0 linux/amd64 BuildDate=unknown\n\n" +
+				`ERROR render of "page" failed: "` + layout + `:2:10": execute of template failed: template: _default/single.html:2:10: executing "main" at <.Params.title.Nope>: can't evaluate field Nope in type string` + "\n" +
+				"Total in 29 ms\n" +
+				`Error: error building site: render: failed to render pages: render of "page" failed: "` + layout + `:2:10": execute of template failed: template: _default/single.html:2:10: executing "main" at <.Params.title.Nope>: can't evaluate field Nope in type string` + "\n",
+			want: BuildErrors{{
+				File:    filepath.Join("layouts", "_default", "single.html"),
+				Line:    2,
+				Column:  10,
+				Message: `execute of template failed: template: _default/single.html:2:10: executing "main" at <.Params.title.Nope>: can't evaluate field Nope in type string`,
+			}},
+		},
+		{
+			name: "content file outside the working directory",
+			output: "Start building sites … \n" +
+				"hugo v0.139.0 linux/amd64 BuildDate=unknown\n\n" +
+				"Total in 18 ms\n" +
+				`Error: error building site: process: readAndProcessContent: "/tmp/site/content/widget.md:1:1": failed to unmarshal YAML: yaml: line 1: did not find expected ',' or ']'` + "\n",
+			want: BuildErrors{{
+				File:    "/tmp/site/content/widget.md",
+				Line:    1,
+				Column:  1,
+				Message: "failed to unmarshal YAML: yaml: line 1: did not find expected ',' or ']'",
+			}},
+		},
+		{
+			name: "no location",
+			output: "Total in 1 ms\n" +
+				"Error: Unable to locate config file or config directory. Perhaps you need to create a new site.\n" +
+				"Run `hugo help new` for details.\n",
+			want: BuildErrors{{
+				Message: "Unable to locate config file or config directory. Perhaps you need to create a new site.",
+			}},
+		},
+		{
+			name:   "no error line",
+			output: "segmentation fault\n",
+			want:   BuildErrors{{Message: "segmentation fault"}},
+		},
+		{
+			name: "no output",
+			want: BuildErrors{{Message: "hugo failed without output"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseHugoErrors([]byte(tt.output)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHugoErrors =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNativeBuild(t *testing.T) {
+	cfg := config.Default()
+	cfg.ContentDir = t.TempDir()
+
+	files := map[string]string{
+		"widget.md":    "---\ntitle: Widget\nimport_path: go.ngs.io/widget\n---\n# Widget\n",
+		"broken.md":    "---\ntitle: Broken\ndescription: a: b\n---\n",
+		"open.md":      "---\ntitle: Open\n",
+		"_index.md":    "# Packages\n",
+		"notes.txt":    "---\n[\n",
+		"widget.ja.md": "---\ntitle: ウィジェット\n---\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(cfg.ContentDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := Native{}.Build(cfg, "", BuildOptions{})
+	errs, ok := err.(BuildErrors)
+	if !ok {
+		t.Fatalf("Build = %v, want BuildErrors", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Build = %v, want the broken and open pages", errs)
+	}
+	if broken := errs[0]; broken.File != filepath.Join(cfg.ContentDir, "broken.md") || broken.Line != 3 || broken.Message == "" {
+		t.Errorf("broken.md reported as %#v, want line 3", broken)
+	}
+	if open := errs[1]; open.File != filepath.Join(cfg.ContentDir, "open.md") || open.Line != 1 {
+		t.Errorf("open.md reported as %#v, want line 1", open)
+	}
+}
+
+func TestPageError(t *testing.T) {
+	pageErr := &hugo.FrontmatterError{File: "content/widget.md", Line: 4, Message: "mapping values are not allowed in this context"}
+	tests := []struct {
+		name string
+		err  error
+		want BuildError
+	}{
+		{"frontmatter", pageErr, BuildError{File: "content/widget.md", Line: 4, Message: pageErr.Message}},
+		{"wrapped", fmt.Errorf("content/widget.md: %w", pageErr), BuildError{File: "content/widget.md", Line: 4, Message: pageErr.Message}},
+		{"other", errors.New("permission denied"), BuildError{Message: "permission denied"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PageError(tt.err); got != tt.want {
+				t.Errorf("PageError = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package site runs Hugo for a domain of the registry. Every domain is built
+// from the shared layouts and hugo.toml; its own settings are layered on top
+// through a generated configuration file. Builders run the build, or only
+// parse the content files where Hugo is not installed, and report failures
+// by file and line.
+package site
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"go.ngs.io/internal/config"
+)
+
+// SharedStaticDir holds the stylesheets and images used by every domain.
+const SharedStaticDir = "static"
+
+// Command returns a hugo command for the named domain (the primary domain if
+// empty) with args, e.g. "--gc", "--minify" or "server". The caller must run
+// cleanup once the command has finished.
+func Command(cfg *config.Config, domain string, args ...string) (cmd *exec.Cmd, cleanup func(), err error) {
+	d, err := cfg.Lookup(domain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overlay, err := os.CreateTemp("", "hugo-*.toml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Hugo configuration: %w", err)
+	}
+	cleanup = func() { os.Remove(overlay.Name()) }
+
+	_, err = overlay.WriteString(hugoConfig(d, d != cfg))
+	if closeErr := overlay.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write Hugo configuration: %w", err)
+	}
+
+	args = append(args, "--config", "hugo.toml,"+overlay.Name())
+	return exec.Command("hugo", args...), cleanup, nil
+}
+
+// hugoConfig returns the settings of domain d overriding hugo.toml. The
+// primary domain keeps the title of hugo.toml. The module mounts replace
+// those of hugo.toml, so the data directory of the domain is mounted in
+// place of the fixed one.
+func hugoConfig(d *config.Config, secondary bool) string {
+	staticDirs := []string{strconv.Quote(SharedStaticDir)}
+	if d.StaticDir != "" && d.StaticDir != SharedStaticDir {
+		staticDirs = append(staticDirs, strconv.Quote(d.StaticDir))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "baseURL = %s\n", strconv.Quote(d.BaseURL()))
+	if secondary {
+		fmt.Fprintf(&b, "title = %s\n", strconv.Quote(d.Site.Title))
+	}
+	fmt.Fprintf(&b, "contentDir = %s\n", strconv.Quote(d.ContentDir))
+	fmt.Fprintf(&b, "staticDir = [%s]\n", strings.Join(staticDirs, ", "))
+	fmt.Fprintf(&b, "publishDir = %s\n", strconv.Quote(d.OutputDir))
+	b.WriteString("\n[params]\n")
+	fmt.Fprintf(&b, "  domain = %s\n", strconv.Quote(d.Domain))
+	b.WriteString("  [params.vanity]\n")
+	fmt.Fprintf(&b, "    author = %s\n", strconv.Quote(d.Site.Author))
+	fmt.Fprintf(&b, "    author_url = %s\n", strconv.Quote(d.Site.AuthorURL))
+	b.WriteString("\n[module]\n")
+	for _, mount := range [][2]string{{d.DataDir, "data"}, {config.DefaultFile, "data/" + config.DefaultFile}} {
+		b.WriteString("  [[module.mounts]]\n")
+		fmt.Fprintf(&b, "    source = %s\n", strconv.Quote(mount[0]))
+		fmt.Fprintf(&b, "    target = %s\n", strconv.Quote(mount[1]))
+	}
+	return b.String()
+}
+
+// Serve runs hugo server for the named domain with args until it is
+// interrupted.
+func Serve(cfg *config.Config, domain string, args ...string) error {
+	cmd, cleanup, err := Command(cfg, domain, append([]string{"server"}, args...)...)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
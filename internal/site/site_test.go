@@ -0,0 +1,41 @@
+package site
+
+import (
+	"testing"
+
+	"go.ngs.io/internal/config"
+)
+
+func TestHugoConfig(t *testing.T) {
+	d := &config.Config{
+		Domain:     "go.example.com",
+		ContentDir: "domains/go.example.com/content",
+		StaticDir:  "domains/go.example.com/static",
+		OutputDir:  "domains/go.example.com/public",
+		DataDir:    "domains/go.example.com/data",
+		Site:       config.Site{Title: "Example modules", Author: "Example", AuthorURL: "https://example.com"},
+	}
+	want := `baseURL = "https://go.example.com/"
+title = "Example modules"
+contentDir = "domains/go.example.com/content"
+staticDir = ["static", "domains/go.example.com/static"]
+publishDir = "domains/go.example.com/public"
+
+[params]
+  domain = "go.example.com"
+  [params.vanity]
+    author = "Example"
+    author_url = "https://example.com"
+
+[module]
+  [[module.mounts]]
+    source = "domains/go.example.com/data"
+    target = "data"
+  [[module.mounts]]
+    source = "vanity.yaml"
+    target = "data/vanity.yaml"
+`
+	if got := hugoConfig(d, true); got != want {
+		t.Errorf("hugoConfig =\n%s\nwant\n%s", got, want)
+	}
+}
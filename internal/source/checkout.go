@@ -0,0 +1,263 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.ngs.io/internal/gomod"
+)
+
+// NameDir is the name of the local checkout source.
+const NameDir = "dir"
+
+// Checkout derives metadata from a local clone of a single repository
+// without network access. The checked-out branch stands for the default
+// branch and is read from the working tree; on a detached HEAD, as in CI
+// checkouts of a tag, the default branch of origin or else the current
+// commit takes its place. The working tree is read, so uncommitted README or
+// LICENSE changes are picked up; other refs are read from git. The repoURL
+// arguments of its methods are ignored.
+type Checkout struct {
+	gitTags
+	root   string
+	prefix string // directory given to NewCheckout, relative to root
+	branch string
+}
+
+// NewCheckout returns the source of the git working tree containing dir.
+func NewCheckout(dir string) (*Checkout, error) {
+	root, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git checkout: %w", dir, err)
+	}
+	prefix, err := runGit(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate %s in its checkout: %w", dir, err)
+	}
+	branch, err := currentBranch(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkout{
+		gitTags: gitTags{dir: func(string) (string, error) { return root, nil }},
+		root:    root,
+		prefix:  strings.TrimSuffix(prefix, "/"),
+		branch:  branch,
+	}, nil
+}
+
+// currentBranch returns the checked-out branch. A detached HEAD falls back
+// to the default branch of origin, then to the commit checked out.
+func currentBranch(root string) (string, error) {
+	if branch, err := runGit(root, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		return branch, nil
+	}
+	if remoteHead, err := runGit(root, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(remoteHead, "origin/"), nil
+	}
+	commit, err := runGit(root, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to read current branch or commit: %w", err)
+	}
+	return commit, nil
+}
+
+func (c *Checkout) Name() string { return NameDir }
+
+// Module returns the module path declared by the go.mod of the directory
+// given to NewCheckout, or else of the nearest directory above it within the
+// working tree, so a nested module is found from its own directory.
+func (c *Checkout) Module() (string, error) {
+	data, err := c.moduleFile()
+	if err != nil {
+		return "", err
+	}
+	modFile, err := gomod.Parse(data)
+	if err != nil {
+		return "", err
+	}
+	if modFile.Path == "" {
+		return "", fmt.Errorf("go.mod declares no module path")
+	}
+	return modFile.Path, nil
+}
+
+// moduleFile reads the nearest go.mod walking up from the given directory.
+func (c *Checkout) moduleFile() ([]byte, error) {
+	for dir := c.prefix; ; dir = path.Dir(dir) {
+		if dir == "." {
+			dir = ""
+		}
+		data, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(dir), "go.mod"))
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read go.mod: %w", err)
+		}
+		if dir == "" {
+			return nil, fmt.Errorf("no go.mod found in %s or above it", path.Join(filepath.ToSlash(c.root), c.prefix))
+		}
+	}
+}
+
+// RemoteURL returns the web URL of the origin remote, e.g.
+// https://github.com/ngs/tool for git@github.com:ngs/tool.git.
+func (c *Checkout) RemoteURL() (string, error) {
+	remote, err := runGit(c.root, "remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to read origin remote: %w", err)
+	}
+	return webURL(remote), nil
+}
+
+// webURL converts the SSH and git forms of a remote URL to https and drops
+// the .git suffix. Local repositories, as file URLs or paths, are kept.
+func webURL(remote string) string {
+	switch {
+	case strings.HasPrefix(remote, "file://"), filepath.IsAbs(remote), strings.HasPrefix(remote, "."):
+		return remote
+	case strings.HasPrefix(remote, "ssh://"), strings.HasPrefix(remote, "git://"):
+		_, rest, _ := strings.Cut(remote, "://")
+		if userHost, repoPath, ok := strings.Cut(rest, "/"); ok {
+			if _, host, found := strings.Cut(userHost, "@"); found {
+				rest = host + "/" + repoPath
+			}
+		}
+		remote = "https://" + rest
+	case !strings.Contains(remote, "://"):
+		// scp-like syntax: git@github.com:owner/repo.git
+		if userHost, repoPath, ok := strings.Cut(remote, ":"); ok {
+			_, host, found := strings.Cut(userHost, "@")
+			if !found {
+				host = userHost
+			}
+			remote = "https://" + host + "/" + repoPath
+		}
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+}
+
+// Repository describes the checkout. A checkout has no repository
+// description, so the first paragraph of the README stands for it.
+func (c *Checkout) Repository(repoURL string) (*Repository, error) {
+	repository := &Repository{
+		DefaultBranch: c.branch,
+		OwnerLogin:    repoOwner(repoURL),
+	}
+	var err error
+	if repository.CreatedAt, repository.UpdatedAt, err = commitDates(c.root); err != nil {
+		return nil, err
+	}
+	readme, err := c.Readme(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	repository.Description = readmeDescription(readme)
+	return repository, nil
+}
+
+var (
+	// markdownImage matches inline images, badges among them
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	// markdownLink matches inline links, keeping the link text
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// readmeDescription returns the first text paragraph of a Markdown README,
+// on one line and with links reduced to their text. Headings, badges, HTML
+// blocks, code blocks, lists and quotes are skipped.
+func readmeDescription(readme string) string {
+	inCode := false
+	for _, paragraph := range strings.Split(strings.ReplaceAll(readme, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		fences := 0
+		for _, line := range strings.Split(paragraph, "\n") {
+			if line = strings.TrimSpace(line); strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+				fences++
+			}
+		}
+		wasCode := inCode
+		if fences%2 == 1 {
+			inCode = !inCode
+		}
+		if wasCode || fences > 0 {
+			continue
+		}
+		if paragraph == "" || strings.ContainsAny(paragraph[:1], "#<>|-*+=") {
+			continue
+		}
+		text := strings.TrimSpace(markdownLink.ReplaceAllString(markdownImage.ReplaceAllString(paragraph, ""), "$1"))
+		if text == "" {
+			continue // badges only
+		}
+		// Setext headings underline their text
+		lines := strings.Split(text, "\n")
+		if last := strings.TrimSpace(lines[len(lines)-1]); len(lines) > 1 && strings.Trim(last, "=-") == "" {
+			continue
+		}
+		return strings.Join(strings.Fields(text), " ")
+	}
+	return ""
+}
+
+func (c *Checkout) Readme(repoURL string) (string, error) {
+	entries, err := c.ListDir(repoURL, "", "")
+	if err != nil {
+		return "", err
+	}
+
+	name := findReadme(entries)
+	if name == "" {
+		return "", nil // No README available
+	}
+
+	content, err := c.File(repoURL, name, "")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// workingTree reports whether ref is read from the working tree.
+func (c *Checkout) workingTree(ref string) bool {
+	return ref == "" || ref == c.branch
+}
+
+func (c *Checkout) File(repoURL, filePath, ref string) ([]byte, error) {
+	if !c.workingTree(ref) {
+		return showFile(c.root, filePath, ref)
+	}
+	content, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(filePath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
+func (c *Checkout) ListDir(repoURL, dirPath, ref string) ([]DirEntry, error) {
+	if !c.workingTree(ref) {
+		return listTree(c.root, dirPath, ref)
+	}
+
+	files, err := os.ReadDir(filepath.Join(c.root, filepath.FromSlash(dirPath)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dirPath, err)
+	}
+	entries := []DirEntry{}
+	for _, file := range files {
+		if file.Name() == ".git" {
+			continue
+		}
+		entries = append(entries, DirEntry{
+			Name: file.Name(),
+			Path: path.Join(dirPath, file.Name()),
+			Dir:  file.IsDir(),
+		})
+	}
+	return entries, nil
+}
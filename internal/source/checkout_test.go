@@ -0,0 +1,141 @@
+package source
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckoutBranch(t *testing.T) {
+	upstream := newTestRepo(t)
+	upstream.commit("2024-01-01T00:00:00Z", map[string]string{"README.md": "# Widget\n"})
+	upstream.git("", "tag", "v1.0.0")
+	upstream.commit("2024-02-01T00:00:00Z", map[string]string{"README.md": "# Widget 2\n"})
+
+	// A clone whose origin records its default branch
+	clone := newTestRepo(t)
+	clone.git("", "fetch", "--quiet", upstream.dir, "main:refs/remotes/origin/main", "tag", "v1.0.0")
+	clone.git("", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	tests := []struct {
+		name string
+		repo *testRepo
+		ref  string
+		want string
+	}{
+		{name: "branch", repo: upstream, ref: "main", want: "main"},
+		{name: "detached HEAD with origin", repo: clone, ref: "v1.0.0", want: "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.repo.git("", "checkout", "--quiet", tt.ref)
+			c, err := NewCheckout(tt.repo.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.branch != tt.want {
+				t.Errorf("branch = %q, want %q", c.branch, tt.want)
+			}
+		})
+	}
+
+	// Without origin, the detached commit stands for the branch
+	upstream.git("", "checkout", "--quiet", "v1.0.0")
+	c, err := NewCheckout(upstream.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := runGit(upstream.dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.branch != commit {
+		t.Errorf("branch = %q, want the commit %s", c.branch, commit)
+	}
+	if readme, err := c.Readme(""); err != nil {
+		t.Fatal(err)
+	} else if readme != "# Widget\n" {
+		t.Errorf("Readme = %q, want the working tree at v1.0.0", readme)
+	}
+}
+
+func TestCheckoutModule(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{
+		"go.mod":             "module go.ngs.io/widget\n",
+		"cmd/widget/main.go": "package main\n",
+		"tool/go.mod":        "module go.ngs.io/widget/tool\n",
+		"tool/internal/x.go": "package internal\n",
+	})
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: "", want: "go.ngs.io/widget"},
+		{dir: "cmd/widget", want: "go.ngs.io/widget"},
+		{dir: "tool", want: "go.ngs.io/widget/tool"},
+		{dir: "tool/internal", want: "go.ngs.io/widget/tool"},
+	}
+	for _, tt := range tests {
+		c, err := NewCheckout(filepath.Join(repo.dir, filepath.FromSlash(tt.dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := c.Module(); err != nil || got != tt.want {
+			t.Errorf("Module from %q = %q, %v; want %q", tt.dir, got, err, tt.want)
+		}
+	}
+
+	bare := newTestRepo(t)
+	bare.commit("2024-01-01T00:00:00Z", map[string]string{"README.md": "# Widget\n"})
+	c, err := NewCheckout(bare.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Module(); err == nil {
+		t.Error("Module without go.mod succeeded")
+	}
+}
+
+func TestCheckoutRepositoryDescription(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{
+		"README.md": "# Widget\n\n[![Go Reference](https://pkg.go.dev/badge/go.ngs.io/widget.svg)](https://pkg.go.dev/go.ngs.io/widget)\n\n" +
+			"Widget renders widgets\nfor [Hugo](https://gohugo.io).\n\n## Install\n",
+	})
+	c, err := NewCheckout(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository, err := c.Repository("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Widget renders widgets for Hugo."; repository.Description != want {
+		t.Errorf("Description = %q, want %q", repository.Description, want)
+	}
+}
+
+func TestReadmeDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"heading only", "# Widget\n", ""},
+		{"first paragraph", "# Widget\n\nRenders widgets.\n\nSecond paragraph.\n", "Renders widgets."},
+		{"setext heading", "Widget\n======\n\nRenders widgets.\n", "Renders widgets."},
+		{"badges and html", "<p align=\"center\"><img src=\"logo.png\"></p>\n\n![CI](https://example.com/ci.svg) ![Go](https://example.com/go.svg)\n\nRenders widgets.\n", "Renders widgets."},
+		{"code block with blank lines", "```go\nwidget.Render()\n\nwidget.Close()\n```\n\nRenders widgets.\n", "Renders widgets."},
+		{"lists and quotes", "- one\n- two\n\n> Note\n\nRenders `widgets`.\n", "Renders `widgets`."},
+		{"windows line endings", "# Widget\r\n\r\nRenders\r\nwidgets.\r\n", "Renders widgets."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readmeDescription(tt.readme); got != tt.want {
+				t.Errorf("readmeDescription = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
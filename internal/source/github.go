@@ -0,0 +1,111 @@
+package source
+
+import (
+	"go.ngs.io/internal/github"
+)
+
+// GitHub reads metadata from the GitHub API through the gh CLI.
+type GitHub struct{}
+
+func (GitHub) Name() string { return NameGitHub }
+
+func (GitHub) Repository(repoURL string) (*Repository, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ghRepo, err := github.GetRepository(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	repository := &Repository{
+		Description:   ghRepo.Description,
+		DefaultBranch: ghRepo.DefaultBranch,
+		OwnerLogin:    ghRepo.Owner.Login,
+		OwnerName:     ghRepo.Owner.Name,
+		CreatedAt:     ghRepo.CreatedAt,
+		UpdatedAt:     ghRepo.UpdatedAt,
+	}
+	// GitHub reports NOASSERTION for licenses it cannot identify
+	if ghRepo.License != nil && ghRepo.License.SPDXID != "NOASSERTION" {
+		repository.License = ghRepo.License.SPDXID
+	}
+
+	return repository, nil
+}
+
+func (GitHub) Versions(repoURL string) ([]Version, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ghVersions, err := github.ListVersions(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(ghVersions))
+	for _, v := range ghVersions {
+		versions = append(versions, Version(v))
+	}
+
+	return versions, nil
+}
+
+func (GitHub) LatestVersion(repoURL string) (string, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	return github.GetLatestVersion(owner, repo)
+}
+
+func (GitHub) Readme(repoURL string) (string, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	return github.GetReadme(owner, repo)
+}
+
+func (GitHub) File(repoURL, path, ref string) ([]byte, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return github.GetFile(owner, repo, path, ref)
+}
+
+func (GitHub) ListDir(repoURL, path, ref string) ([]DirEntry, error) {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ghEntries, err := github.ListDir(owner, repo, path, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]DirEntry, 0, len(ghEntries))
+	for _, e := range ghEntries {
+		entries = append(entries, DirEntry{Name: e.Name, Path: e.Path, Dir: e.Type == "dir"})
+	}
+
+	return entries, nil
+}
+
+func (GitHub) ReleaseURL(repoURL, tag string) string {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return ""
+	}
+
+	return github.ReleaseURL(owner, repo, tag)
+}
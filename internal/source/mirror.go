@@ -0,0 +1,343 @@
+package source
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.ngs.io/internal/github"
+	"go.ngs.io/internal/readme"
+	"golang.org/x/mod/semver"
+)
+
+// defaultDescription is what git writes to the description file of a new
+// repository.
+const defaultDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// Mirror derives metadata from bare git mirrors kept in a cache directory.
+// Each repository is cloned or fetched once per process.
+type Mirror struct {
+	gitTags
+	cacheDir string
+	synced   map[string]bool
+}
+
+func NewMirror(cacheDir string) (*Mirror, error) {
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine cache directory: %w", err)
+		}
+		cacheDir = filepath.Join(userCacheDir, "go.ngs.io", "mirrors")
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	m := &Mirror{cacheDir: cacheDir, synced: map[string]bool{}}
+	m.gitTags = gitTags{dir: m.syncedDir}
+	return m, nil
+}
+
+func (m *Mirror) Name() string { return NameGit }
+
+// Dir returns the mirror directory of a repository URL.
+func (m *Mirror) Dir(repoURL string) string {
+	base := strings.TrimSuffix(path.Base(strings.TrimSuffix(repoURL, "/")), ".git")
+	sum := sha256.Sum256([]byte(repoURL))
+	return filepath.Join(m.cacheDir, fmt.Sprintf("%s-%x.git", base, sum[:6]))
+}
+
+// Sync clones the repository as a bare mirror or fetches updates into an
+// existing one.
+func (m *Mirror) Sync(repoURL string) error {
+	if m.synced[repoURL] {
+		return nil
+	}
+
+	dir := m.Dir(repoURL)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if err := checkRepoURL(repoURL); err != nil {
+			return err
+		}
+		if _, err := runGit("", "clone", "--quiet", "--mirror", "--", repoURL, dir); err != nil {
+			return fmt.Errorf("failed to mirror %s: %w", repoURL, err)
+		}
+	} else if _, err := runGit(dir, "remote", "update", "--prune"); err != nil {
+		return fmt.Errorf("failed to update mirror of %s: %w", repoURL, err)
+	}
+
+	m.synced[repoURL] = true
+	return nil
+}
+
+// checkRepoURL rejects repository URLs git would not fetch as a plain
+// repository: arguments read as options, and transports such as ext:: that
+// run commands. Repository URLs come from content files, so they are not
+// trusted.
+func checkRepoURL(repoURL string) error {
+	if strings.HasPrefix(repoURL, "-") {
+		return fmt.Errorf("invalid repository URL %q", repoURL)
+	}
+	if filepath.IsAbs(repoURL) && !strings.Contains(repoURL, "::") {
+		return nil // Local repository
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return fmt.Errorf("invalid repository URL %q: %w", repoURL, err)
+	}
+	switch u.Scheme {
+	case "https", "http", "ssh", "git", "file":
+		return nil
+	}
+	return fmt.Errorf("invalid repository URL %q: use an https, ssh, git or file URL or a local path", repoURL)
+}
+
+// syncedDir returns the mirror directory of a repository URL after syncing
+// it.
+func (m *Mirror) syncedDir(repoURL string) (string, error) {
+	if err := m.Sync(repoURL); err != nil {
+		return "", err
+	}
+	return m.Dir(repoURL), nil
+}
+
+func (m *Mirror) git(repoURL string, args ...string) (string, error) {
+	if err := m.Sync(repoURL); err != nil {
+		return "", err
+	}
+	return runGit(m.Dir(repoURL), args...)
+}
+
+func (m *Mirror) Repository(repoURL string) (*Repository, error) {
+	defaultBranch, err := m.git(repoURL, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default branch: %w", err)
+	}
+
+	repository := &Repository{
+		DefaultBranch: defaultBranch,
+		OwnerLogin:    repoOwner(repoURL),
+	}
+	if repository.CreatedAt, repository.UpdatedAt, err = commitDates(m.Dir(repoURL)); err != nil {
+		return nil, err
+	}
+
+	description, err := os.ReadFile(filepath.Join(m.Dir(repoURL), "description"))
+	if err == nil {
+		repository.Description = strings.TrimSpace(string(description))
+		if repository.Description == defaultDescription {
+			repository.Description = ""
+		}
+	}
+
+	return repository, nil
+}
+
+// commitDates returns the dates of the first and the latest commit of HEAD.
+func commitDates(dir string) (created, updated time.Time, err error) {
+	dates, err := runGit(dir, "log", "--format=%cI", "HEAD")
+	if err != nil {
+		return created, updated, fmt.Errorf("failed to read commit dates: %w", err)
+	}
+	lines := strings.Split(dates, "\n")
+
+	if updated, err = time.Parse(time.RFC3339, lines[0]); err != nil {
+		return created, updated, fmt.Errorf("failed to parse commit date: %w", err)
+	}
+	if created, err = time.Parse(time.RFC3339, lines[len(lines)-1]); err != nil {
+		return created, updated, fmt.Errorf("failed to parse commit date: %w", err)
+	}
+	return created.UTC(), updated.UTC(), nil
+}
+
+// tagVersions returns the semantic version tags of the repository in dir,
+// newest first.
+func tagVersions(dir string) ([]Version, error) {
+	out, err := runGit(dir, "for-each-ref",
+		"--format=%(refname:short)%09%(objecttype)%09%(creatordate:iso-strict)%09%(contents:subject)",
+		"refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	versions := []Version{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 3 || !semver.IsValid(fields[0]) {
+			continue
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date of %s: %w", fields[0], err)
+		}
+
+		version := Version{
+			Name:       fields[0],
+			Date:       date.UTC(),
+			Prerelease: semver.Prerelease(fields[0]) != "",
+		}
+		// Annotated tags carry a message that serves as the release name
+		if fields[1] == "tag" && len(fields) == 4 {
+			version.ReleaseName = fields[3]
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i].Name, versions[j].Name) > 0
+	})
+
+	return versions, nil
+}
+
+func (m *Mirror) Readme(repoURL string) (string, error) {
+	entries, err := m.ListDir(repoURL, "", "")
+	if err != nil {
+		return "", err
+	}
+
+	name := findReadme(entries)
+	if name == "" {
+		return "", nil // No README available
+	}
+
+	content, err := m.File(repoURL, name, "")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (m *Mirror) File(repoURL, filePath, ref string) ([]byte, error) {
+	if err := m.Sync(repoURL); err != nil {
+		return nil, err
+	}
+	return showFile(m.Dir(repoURL), filePath, ref)
+}
+
+// showFile reads a file of the repository in dir at ref.
+func showFile(dir, filePath, ref string) ([]byte, error) {
+	cmd := exec.Command("git", "show", revision(ref)+":"+filePath)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", filePath, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+func (m *Mirror) ListDir(repoURL, dirPath, ref string) ([]DirEntry, error) {
+	if err := m.Sync(repoURL); err != nil {
+		return nil, err
+	}
+	return listTree(m.Dir(repoURL), dirPath, ref)
+}
+
+// listTree lists a directory of the repository in dir at ref.
+func listTree(dir, dirPath, ref string) ([]DirEntry, error) {
+	treeish := revision(ref)
+	if dirPath != "" {
+		treeish += ":" + dirPath
+	}
+
+	out, err := runGit(dir, "ls-tree", treeish)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dirPath, err)
+	}
+
+	entries := []DirEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, DirEntry{
+			Name: name,
+			Path: path.Join(dirPath, name),
+			Dir:  fields[1] == "tree",
+		})
+	}
+
+	return entries, nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func revision(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return ref
+}
+
+// findReadme picks the README the way GitHub does, preferring Markdown and
+// falling back to localized variants such as README.ja.md last.
+func findReadme(entries []DirEntry) string {
+	var found, localized string
+	for _, entry := range entries {
+		if entry.Dir {
+			continue
+		}
+		name := strings.ToLower(entry.Name)
+		if name == "readme.md" {
+			return entry.Name
+		}
+		if name != "readme" && !strings.HasPrefix(name, "readme.") {
+			continue
+		}
+		if readme.Language(entry.Name) != "" {
+			if localized == "" {
+				localized = entry.Name
+			}
+		} else if found == "" {
+			found = entry.Name
+		}
+	}
+	if found == "" {
+		return localized
+	}
+	return found
+}
+
+// repoOwner returns the path segment preceding the repository name.
+func repoOwner(repoURL string) string {
+	if owner, _, err := github.ParseRepoURL(repoURL); err == nil {
+		return owner
+	}
+	return path.Base(path.Dir(strings.TrimSuffix(repoURL, "/")))
+}
@@ -0,0 +1,275 @@
+package source
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRepo is a git repository created in a temporary directory.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Keep the configuration of the user out of the tests
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("", "init", "--quiet", "--initial-branch=main")
+	return r
+}
+
+// git runs a git command in the repository, dating commits and tags at date.
+func (r *testRepo) git(date string, args ...string) {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if date != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// commit writes files and commits them at date.
+func (r *testRepo) commit(date string, files map[string]string) {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git(date, "add", "--all")
+	r.git(date, "commit", "--quiet", "--message", "Update")
+}
+
+func date(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newTestMirror(t *testing.T, cacheDir string) *Mirror {
+	t.Helper()
+	m, err := NewMirror(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMirrorSync(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{"README.md": "# Widget\n"})
+	cacheDir := t.TempDir()
+
+	m := newTestMirror(t, cacheDir)
+	if err := m.Sync(repo.dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir(repo.dir), "HEAD")); err != nil {
+		t.Fatalf("Sync created no mirror: %v", err)
+	}
+
+	repo.commit("2024-02-01T00:00:00Z", map[string]string{"README.md": "# Widget 2\n"})
+
+	// A mirror fetches once per process
+	if readme, err := m.Readme(repo.dir); err != nil {
+		t.Fatal(err)
+	} else if readme != "# Widget\n" {
+		t.Errorf("Readme after the first Sync = %q, want the first commit", readme)
+	}
+
+	// The next process updates the existing mirror
+	m = newTestMirror(t, cacheDir)
+	if readme, err := m.Readme(repo.dir); err != nil {
+		t.Fatal(err)
+	} else if readme != "# Widget 2\n" {
+		t.Errorf("Readme after updating the mirror = %q, want the second commit", readme)
+	}
+}
+
+func TestMirrorSyncMissingRepository(t *testing.T) {
+	m := newTestMirror(t, t.TempDir())
+	if err := m.Sync(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Sync of a missing repository succeeded")
+	}
+}
+
+func TestMirrorSyncRejectsURLs(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "pwned")
+	for _, repoURL := range []string{
+		"--upload-pack=touch " + marker,
+		"-uhello",
+		"ext::sh -c touch% " + marker,
+		"fd::3",
+		"/tmp/ext::sh",
+		"relative/repository",
+		"ftp://example.com/widget.git",
+	} {
+		m := newTestMirror(t, t.TempDir())
+		if err := m.Sync(repoURL); err == nil {
+			t.Errorf("Sync(%q) succeeded", repoURL)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("a repository URL ran a command")
+	}
+
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{"README.md": "# Widget\n"})
+	for _, repoURL := range []string{repo.dir, "file://" + filepath.ToSlash(repo.dir)} {
+		if err := newTestMirror(t, t.TempDir()).Sync(repoURL); err != nil {
+			t.Errorf("Sync(%q) = %v", repoURL, err)
+		}
+	}
+}
+
+func TestMirrorVersions(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{"go.mod": "module example.com/widget\n"})
+	repo.git("2024-01-02T00:00:00Z", "tag", "--annotate", "v1.0.0", "--message", "First release")
+	repo.commit("2024-03-01T00:00:00Z", map[string]string{"widget.go": "package widget\n"})
+	repo.git("", "tag", "v1.1.0-rc.1")
+	repo.git("", "tag", "nightly")
+	repo.commit("2024-04-01T00:00:00Z", map[string]string{"widget.go": "package widget // v1.1\n"})
+	repo.git("2024-04-02T00:00:00Z", "tag", "--annotate", "v1.1.0", "--message", "Second release")
+
+	m := newTestMirror(t, t.TempDir())
+	versions, err := m.Versions(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Version{
+		{Name: "v1.1.0", Date: date("2024-04-02T00:00:00Z"), ReleaseName: "Second release"},
+		// Lightweight tags are dated by their commit and have no release name
+		{Name: "v1.1.0-rc.1", Date: date("2024-03-01T00:00:00Z"), Prerelease: true},
+		{Name: "v1.0.0", Date: date("2024-01-02T00:00:00Z"), ReleaseName: "First release"},
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("Versions = %+v, want %+v", versions, want)
+	}
+
+	latest, err := m.LatestVersion(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "v1.1.0" {
+		t.Errorf("LatestVersion = %q, want v1.1.0", latest)
+	}
+
+	if got, want := m.ReleaseURL("https://github.com/ngs/widget", "v1.0.0"), "https://github.com/ngs/widget/releases/tag/v1.0.0"; got != want {
+		t.Errorf("ReleaseURL = %q, want %q", got, want)
+	}
+	if got := m.ReleaseURL(repo.dir, "v1.0.0"); got != "" {
+		t.Errorf("ReleaseURL outside GitHub = %q, want none", got)
+	}
+}
+
+func TestMirrorFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{
+		"README.ja.md":       "# ウィジェット\n",
+		"README.md":          "# Widget v1\n",
+		"cmd/widget/main.go": "package main\n",
+	})
+	repo.git("", "tag", "v1.0.0")
+	repo.commit("2024-02-01T00:00:00Z", map[string]string{"README.md": "# Widget v2\n"})
+
+	m := newTestMirror(t, t.TempDir())
+
+	readme, err := m.Readme(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readme != "# Widget v2\n" {
+		t.Errorf("Readme = %q, want README.md of the default branch", readme)
+	}
+
+	content, err := m.File(repo.dir, "README.md", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Widget v1\n" {
+		t.Errorf("File at v1.0.0 = %q, want the tagged README.md", content)
+	}
+	if _, err := m.File(repo.dir, "missing.go", ""); err == nil {
+		t.Error("File of a missing file succeeded")
+	}
+
+	entries, err := m.ListDir(repo.dir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DirEntry{
+		{Name: "README.ja.md", Path: "README.ja.md"},
+		{Name: "README.md", Path: "README.md"},
+		{Name: "cmd", Path: "cmd", Dir: true},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListDir = %+v, want %+v", entries, want)
+	}
+
+	entries, err = m.ListDir(repo.dir, "cmd/widget", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []DirEntry{{Name: "main.go", Path: "cmd/widget/main.go"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListDir of cmd/widget = %+v, want %+v", entries, want)
+	}
+}
+
+func TestMirrorReadmeMissing(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2024-01-01T00:00:00Z", map[string]string{"widget.go": "package widget\n"})
+
+	readme, err := newTestMirror(t, t.TempDir()).Readme(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readme != "" {
+		t.Errorf("Readme = %q, want none", readme)
+	}
+}
+
+func TestMirrorRepository(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("2023-05-01T09:00:00+09:00", map[string]string{"README.md": "# Widget\n"})
+	repo.commit("2024-06-01T12:00:00-07:00", map[string]string{"widget.go": "package widget\n"})
+
+	repository, err := newTestMirror(t, t.TempDir()).Repository(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Repository{
+		DefaultBranch: "main",
+		OwnerLogin:    filepath.Base(filepath.Dir(repo.dir)),
+		CreatedAt:     date("2023-05-01T00:00:00Z"),
+		UpdatedAt:     date("2024-06-01T19:00:00Z"),
+	}
+	if !reflect.DeepEqual(repository, want) {
+		t.Errorf("Repository = %+v, want %+v", repository, want)
+	}
+}
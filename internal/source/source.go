@@ -0,0 +1,106 @@
+// Package source abstracts where package metadata comes from: the GitHub
+// API, local bare git mirrors of the package repositories or a local
+// checkout.
+package source
+
+import (
+	"fmt"
+	"time"
+
+	"go.ngs.io/internal/github"
+)
+
+const (
+	NameGitHub = "github"
+	NameGit    = "git"
+)
+
+// Source provides repository metadata for a repository URL. An empty ref
+// selects the default branch.
+type Source interface {
+	Name() string
+	Repository(repoURL string) (*Repository, error)
+	Versions(repoURL string) ([]Version, error)
+	LatestVersion(repoURL string) (string, error)
+	Readme(repoURL string) (string, error)
+	File(repoURL, path, ref string) ([]byte, error)
+	ListDir(repoURL, path, ref string) ([]DirEntry, error)
+	ReleaseURL(repoURL, tag string) string
+}
+
+// Repository is the repository-level metadata. Fields a source cannot
+// provide are left empty.
+type Repository struct {
+	Description   string
+	DefaultBranch string
+	License       string // SPDX ID
+	OwnerLogin    string
+	OwnerName     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Version is a semantic version tag together with its release metadata.
+type Version struct {
+	Name        string
+	Date        time.Time
+	ReleaseName string
+	Prerelease  bool
+}
+
+type DirEntry struct {
+	Name string
+	Path string
+	Dir  bool
+}
+
+// New returns the source with the given name. cacheDir is where the git
+// source keeps its mirrors; an empty value selects the user cache directory.
+func New(name, cacheDir string) (Source, error) {
+	switch name {
+	case NameGitHub, "":
+		return GitHub{}, nil
+	case NameGit:
+		return NewMirror(cacheDir)
+	default:
+		return nil, fmt.Errorf("unknown metadata source: %s", name)
+	}
+}
+
+// gitTags implements the tag based methods of the sources that read a local
+// git repository. dir returns the repository of a repository URL, fetching
+// it first if needed.
+type gitTags struct {
+	dir func(repoURL string) (string, error)
+}
+
+func (g gitTags) Versions(repoURL string) ([]Version, error) {
+	dir, err := g.dir(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	return tagVersions(dir)
+}
+
+// LatestVersion returns the most recently created tag, whether or not it is
+// a semantic version.
+func (g gitTags) LatestVersion(repoURL string) (string, error) {
+	dir, err := g.dir(repoURL)
+	if err != nil {
+		return "", err
+	}
+	out, err := runGit(dir, "for-each-ref", "--sort=-creatordate", "--count=1", "--format=%(refname:short)", "refs/tags")
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	return out, nil
+}
+
+// ReleaseURL links GitHub release pages; other forges have no known layout.
+func (gitTags) ReleaseURL(repoURL, tag string) string {
+	owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return ""
+	}
+	return github.ReleaseURL(owner, repo, tag)
+}